@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left for multipart boundaries and headers on top of the file itself.
+const multipartOverhead = 1 << 20
+
+// UploadAttachment attaches a file to the task with the provided ID.
+// It expects a multipart/form-data request with the file in the "file" field.
+// The file is streamed to storage part by part instead of being buffered by the form parser.
+// If the ID or the request is invalid, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// If the file is too large, it returns a 413 Request Entity Too Large.
+// If the file type is not allowed, it returns a 415 Unsupported Media Type.
+// The attachment metadata is returned with status 201 Created.
+func UploadAttachment(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, data.MaxAttachmentSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.Error(problem.InvalidBody("expected a multipart/form-data request"))
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.Error(problem.Validation(problem.FieldError{Field: "file", Message: "is required"}))
+			return
+		}
+		if err != nil {
+			c.Error(uploadError(err))
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := data.AddAttachment(c.Request.Context(), taskID, filepath.Base(part.FileName()), part)
+		part.Close()
+		if err != nil {
+			c.Error(uploadError(err))
+			return
+		}
+		c.JSON(http.StatusCreated, attachment)
+		return
+	}
+}
+
+// uploadError describes an error raised while receiving an upload.
+// A body cut by the size limit is reported like a stored attachment exceeding it.
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return data.ErrAttachmentTooLarge
+	}
+	return err
+}
+
+// GetAttachments lists the metadata of the attachments of the task with the provided ID.
+func GetAttachments(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := data.GetAttachments(c.Request.Context(), taskID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment streams the content of an attachment back to the client.
+// If either ID is invalid, it returns a 400 Bad Request.
+// If the attachment is not found, it returns a 404 Not Found.
+func DownloadAttachment(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := objectIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := data.OpenAttachment(c.Request.Context(), taskID, attachmentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition": disposition,
+		"ETag":                `"` + attachment.SHA256 + `"`,
+	})
+}
+
+// DeleteAttachment deletes an attachment of the task with the provided ID.
+// If either ID is invalid, it returns a 400 Bad Request.
+// If the attachment is not found, it returns a 404 Not Found.
+func DeleteAttachment(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := objectIDParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	if err := data.DeleteAttachment(c.Request.Context(), taskID, attachmentID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted successfully"})
+}
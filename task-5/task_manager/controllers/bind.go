@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"task_manager/problem"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bindJSON decodes the JSON body of the request into v and checks its binding tags.
+// It reports an invalid_body problem and returns false if the body can't be decoded,
+// pointing at the offending field when the decoder tells which one it is, and a
+// validation_failed problem listing every invalid field if the tags are not satisfied.
+func bindJSON(c *gin.Context, v interface{}) bool {
+	err := c.ShouldBindJSON(v)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &validationErrs):
+		c.Error(problem.Validation(fieldErrors(validationErrs)...))
+	case errors.As(err, &typeErr):
+		c.Error(problem.InvalidBody("a field of the request body has the wrong type",
+			problem.FieldError{Field: typeErr.Field, Message: "has the wrong type, got a JSON " + typeErr.Value}))
+	case errors.As(err, &timeErr):
+		c.Error(problem.InvalidBody("times must be formatted as RFC 3339, e.g. 2024-09-01T00:00:00Z"))
+	case errors.Is(err, io.EOF):
+		c.Error(problem.InvalidBody("the request body is empty"))
+	default:
+		c.Error(problem.InvalidBody("the request body is not valid JSON"))
+	}
+	return false
+}
+
+// objectIDParam parses the path parameter name as an ObjectID.
+// It reports an invalid_parameter problem and returns false if it is malformed.
+func objectIDParam(c *gin.Context, name string) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param(name))
+	if err != nil {
+		c.Error(problem.InvalidParameter(name, "must be a valid ObjectID"))
+		return id, false
+	}
+	return id, true
+}
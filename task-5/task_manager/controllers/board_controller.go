@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"task_manager/data"
+	"task_manager/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBoard returns the tasks grouped into status columns, each column in board order.
+// The optional "columns" query parameter is a comma separated list of statuses selecting
+// the columns to return and their order, e.g. "?columns=todo,in progress,done".
+func GetBoard(c *gin.Context) {
+	var columns []string
+	for _, status := range strings.Split(c.Query("columns"), ",") {
+		if status = models.NormalizeStatus(status); status != "" {
+			columns = append(columns, status)
+		}
+	}
+
+	board, err := data.GetBoard(c.Request.Context(), columns)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+// MoveTask moves the task with the provided ID to another column and/or position of the board.
+// It expects a JSON payload such as {"status": "done", "after_id": "...", "before_id": "..."}.
+// If the payload or the ID is invalid, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// If a neighbour task is not in the target column, it returns a 409 Conflict.
+// The moved task is returned with status 200 OK.
+func MoveTask(c *gin.Context) {
+	objID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var move models.TaskMove
+	if !bindJSON(c, &move) {
+		return
+	}
+	if (move.AfterID != nil && *move.AfterID == objID) || (move.BeforeID != nil && *move.BeforeID == objID) {
+		c.Error(data.ErrInvalidMove)
+		return
+	}
+
+	task, err := data.MoveTask(c.Request.Context(), objID, move)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
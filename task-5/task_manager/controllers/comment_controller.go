@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCommentLimit = 20
+	maxCommentLimit     = 100
+)
+
+// CreateComment adds a comment to the task with the provided ID.
+// The author is taken from the X-User-ID header and the JSON payload must contain a non empty body.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the payload or the ID is invalid, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// The created comment is returned with status 201 Created.
+func CreateComment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var payload models.CommentBody
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if strings.TrimSpace(payload.Body) == "" {
+		c.Error(problem.Validation(problem.FieldError{Field: "body", Message: "can't be empty"}))
+		return
+	}
+
+	comment, err := data.AddComment(c.Request.Context(), taskID, user, payload.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComments lists the comments of the task with the provided ID, oldest first.
+// The "page" (starting at 1) and "limit" (at most 100, 20 by default) query parameters select the page.
+// If the ID or the pagination parameters are invalid, it returns a 400 Bad Request.
+func GetComments(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.Error(problem.InvalidParameter("page", "must be a positive integer"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultCommentLimit)))
+	if err != nil || limit < 1 || limit > maxCommentLimit {
+		c.Error(problem.InvalidParameter("limit", "must be between 1 and 100"))
+		return
+	}
+
+	comments, err := data.GetComments(c.Request.Context(), taskID, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// UpdateComment edits the body of a comment. Only the author of the comment may edit it.
+// If the X-User-ID header is missing, it returns a 401 Unauthorized.
+// If the caller is not the author, it returns a 403 Forbidden.
+// If the comment is not found, it returns a 404 Not Found.
+// The updated comment is returned with status 200 OK.
+func UpdateComment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := objectIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+	var payload models.CommentBody
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if strings.TrimSpace(payload.Body) == "" {
+		c.Error(problem.Validation(problem.FieldError{Field: "body", Message: "can't be empty"}))
+		return
+	}
+
+	comment, err := data.UpdateComment(c.Request.Context(), taskID, commentID, user, payload.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment deletes a comment of the task with the provided ID. Only the author of the comment may delete it.
+// If the X-User-ID header is missing, it returns a 401 Unauthorized.
+// If either ID is invalid, it returns a 400 Bad Request.
+// If the caller is not the author, it returns a 403 Forbidden.
+// If the comment is not found, it returns a 404 Not Found.
+func DeleteComment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := objectIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+
+	if err := data.DeleteComment(c.Request.Context(), taskID, commentID, user); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted successfully"})
+}
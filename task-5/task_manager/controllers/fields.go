@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+	"task_manager/models"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseFields splits a comma separated list of fields, as received in the "fields" query parameter.
+func parseFields(raw string) []string {
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// checkFields makes sure every field is the JSON name of a field of models.Task.
+// It reports an invalid_parameter problem and returns false if a field is unknown.
+func checkFields(c *gin.Context, fields []string) bool {
+	if err := fieldsError(fields); err != nil {
+		c.Error(err)
+		return false
+	}
+	return true
+}
+
+// fieldsError returns an invalid_parameter problem naming the first field which is not the JSON
+// name of a field of models.Task, or nil if every field is known.
+func fieldsError(fields []string) error {
+	for _, field := range fields {
+		if _, known := models.TaskFields[field]; !known {
+			return problem.InvalidParameter("fields", "unknown field '"+field+"'")
+		}
+	}
+	return nil
+}
+
+// selectFields keeps only the given JSON fields of each task. The id is always kept.
+// With no fields, the tasks are returned untouched.
+func selectFields(tasks []models.Task, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return tasks, nil
+	}
+	selected := make([]map[string]json.RawMessage, 0, len(tasks))
+	for _, task := range tasks {
+		kept, err := selectTaskFields(task, fields)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, kept)
+	}
+	return selected, nil
+}
+
+// selectTaskFields keeps only the given JSON fields of a task, plus its id.
+func selectTaskFields(task models.Task, fields []string) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+	kept := map[string]json.RawMessage{"id": all["id"]}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			kept[field] = value
+		}
+	}
+	return kept, nil
+}
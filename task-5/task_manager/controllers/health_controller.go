@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"task_manager/data"
+
+	"github.com/gin-gonic/gin"
+)
+
+// draining is set once the server starts shutting down.
+var draining atomic.Bool
+
+// Drain makes the readiness probe fail, so no new traffic is routed to a server shutting down
+// while it finishes the requests in progress.
+func Drain() {
+	draining.Store(true)
+}
+
+// Healthz is the liveness probe: it answers 200 OK as long as the process serves requests,
+// whatever the state of its dependencies.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz is the readiness probe. It pings MongoDB and reports the status of each dependency,
+// with 200 OK when all of them are up and 503 Service Unavailable otherwise or while draining.
+func Readyz(c *gin.Context) {
+	status, dependencies := "ready", gin.H{"mongo": "up"}
+	if err := data.Ping(c.Request.Context()); err != nil {
+		status, dependencies["mongo"] = "unavailable", "down"
+	}
+	if draining.Load() {
+		status = "draining"
+	}
+	code := http.StatusOK
+	if status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "dependencies": dependencies})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications lists the notifications of the user given in the X-User-ID header, newest first.
+// With "unread=true" only the notifications not yet marked as read are returned.
+// If the header is missing, it returns a 401 Unauthorized.
+func GetNotifications(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+
+	notifications, err := data.GetNotifications(c.Request.Context(), user, c.Query("unread") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead marks a notification of the user given in the X-User-ID header as read.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the user has no notification with the provided ID, it returns a 404 Not Found.
+func MarkNotificationRead(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	id, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := data.MarkNotificationRead(c.Request.Context(), user, id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "marked as read"})
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchTasks runs a full-text search over the task titles and descriptions.
+// The "q" query parameter holds the search, where "quoted phrases" must all appear and
+// -terms must not appear; "limit" caps the number of results (20 by default, at most 100).
+// Results are sorted by relevance and come with highlighted snippets of the matching fields.
+// If q is missing or limit is invalid, it returns a 400 Bad Request.
+func SearchTasks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.Error(problem.InvalidParameter("q", "can't be empty"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		c.Error(problem.InvalidParameter("limit", "must be between 1 and 100"))
+		return
+	}
+
+	results, err := data.SearchTasks(c.Request.Context(), q, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"task_manager/data"
+	"task_manager/problem"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultStatsPeriod is the period covered by the statistics when "from" is not given.
+	defaultStatsPeriod = 28 * 24 * time.Hour
+	// maxStatsPeriod bounds the period so the burndown series stays reasonably small.
+	maxStatsPeriod = 366 * 24 * time.Hour
+)
+
+// GetStats returns the task statistics over a period: completion rate, average cycle time,
+// overdue count, tasks created and completed per week and a daily burndown series.
+// Query parameters:
+//   - from, to: the period, as YYYY-MM-DD dates or RFC 3339 times. It defaults to the last 28 days
+//     and can't exceed a year
+//   - tag: restrict the statistics to the tasks carrying the tag, e.g. the tag of a project
+func GetStats(c *gin.Context) {
+	from, to, ok := dateRange(c)
+	if !ok {
+		return
+	}
+	from, to, err := StatsPeriod(from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stats, err := data.GetStats(c.Request.Context(), from, to, strings.ToLower(strings.TrimSpace(c.Query("tag"))))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// StatsPeriod completes the bounds of a statistics period, either of which may be zero: the period
+// ends now and covers the 28 days before its end by default.
+// It returns an invalid_parameter problem if from is not before to or the period exceeds a year.
+func StatsPeriod(from time.Time, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultStatsPeriod)
+	}
+	if !from.Before(to) {
+		return from, to, problem.InvalidParameter("from", "must be before to")
+	}
+	if to.Sub(from) > maxStatsPeriod {
+		return from, to, problem.InvalidParameter("from", "the period can't exceed a year")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+	"regexp"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// AddTaskTags adds tags to the task with the provided ID.
+// It expects a JSON payload of the form {"tags": ["backend", "urgent"]}.
+// If the payload or the ID is invalid, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// The updated task is returned with status 200 OK.
+func AddTaskTags(c *gin.Context) {
+	objID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var payload models.TagList
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if len(payload.Tags) == 0 {
+		c.Error(problem.Validation(problem.FieldError{Field: "tags", Message: "can't be empty"}))
+		return
+	}
+
+	task, err := data.AddTagsToTask(c.Request.Context(), objID, payload.Tags)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// RemoveTaskTag removes the tag given in the URL from the task with the provided ID.
+// If the ID is invalid, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// The updated task is returned with status 200 OK.
+func RemoveTaskTag(c *gin.Context) {
+	objID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	task, err := data.RemoveTagFromTask(c.Request.Context(), objID, c.Param("tag"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// GetTags returns the tag catalogue: every tag with its color and the number of tasks using it.
+func GetTags(c *gin.Context) {
+	tags, err := data.GetTagCatalogue(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// UpdateTag sets the color of the tag given in the URL.
+// It expects a JSON payload of the form {"color": "#ff0000"}.
+// If the color is not a hex color of the form #rrggbb, it returns a 400 Bad Request.
+func UpdateTag(c *gin.Context) {
+	var payload struct {
+		Color string `json:"color"`
+	}
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if !colorPattern.MatchString(payload.Color) {
+		c.Error(problem.Validation(problem.FieldError{Field: "color", Message: "must be of the form #rrggbb"}))
+		return
+	}
+
+	tag, err := data.SetTagColor(c.Request.Context(), c.Param("tag"), payload.Color)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
@@ -1,112 +1,201 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"task_manager/data"
 	"task_manager/models"
+	"task_manager/problem"
+	"task_manager/taskquery"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // GetTasks retrieves all tasks from the data source.
+// The result can be narrowed down with the "tags" query parameter, a comma separated list of tags,
+// and "match" which is either "any" (the default) or "all".
+// The "assignee" query parameter returns the tasks assigned to a user; "assignee=me" stands for
+// the user given in the X-User-ID header.
+// With "sort=smart" open tasks come first, ranked by a score combining priority and due date.
+// The "query" parameter takes an advanced filter in the task query language, such as
+// `status:done due<2024-09-01 tag:backend "release notes"`; a malformed query returns a
+// 400 Bad Request giving the column of the problem in "position".
+// The "fields" parameter, a comma separated list such as "id,title,status", restricts the fields
+// returned; only those fields are read from the database.
 // It returns a JSON response containing the tasks on success,
 // or an error message with a status code on failure.
 func GetTasks(c *gin.Context) {
-	tasks, err := data.GetAllTasks()
+	options := models.TaskListOptions{
+		Tags:     data.ParseTags(c.Query("tags")),
+		Match:    c.Query("match"),
+		Assignee: c.Query("assignee"),
+		Query:    c.Query("query"),
+		Sort:     c.Query("sort"),
+		Fields:   parseFields(c.Query("fields")),
+	}
+	filter, ok := buildTaskFilter(c, options)
+	if !ok {
+		return
+	}
+
+	tasks, err := data.GetAllTasks(c.Request.Context(), filter)
 	if err != nil {
 
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to fetch tasks"})
+		c.Error(err)
+		return
+	}
+	selected, err := selectFields(tasks, filter.Fields)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusOK, selected)
+}
+
+// buildTaskFilter validates task list options and turns them into a data.TaskFilter,
+// resolving "me" to the user given in the X-User-ID header.
+// It reports a 400 Bad Request, or a 401 Unauthorized when "me" is used without the header,
+// and returns false if the options are invalid.
+func buildTaskFilter(c *gin.Context, options models.TaskListOptions) (data.TaskFilter, bool) {
+	user, _ := currentUser(c)
+	filter, err := NewTaskFilter(options, user)
+	if err != nil {
+		c.Error(err)
+		return filter, false
+	}
+	return filter, true
+}
+
+// NewTaskFilter validates task list options and turns them into a data.TaskFilter, resolving "me"
+// to user, the user making the request or "" if unknown. It is shared by every API serving tasks,
+// so the options mean the same whatever the transport.
+// The error is a *problem.Error: invalid_parameter for a malformed option, or missing_user when
+// "me" is used without a user.
+func NewTaskFilter(options models.TaskListOptions, user string) (data.TaskFilter, error) {
+	filter := data.TaskFilter{Tags: options.Tags, Assignee: options.Assignee, Sort: options.Sort, Fields: options.Fields}
+	switch options.Match {
+	case "", "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return filter, problem.InvalidParameter("match", "must be either 'any' or 'all'")
+	}
+	if filter.Assignee == "me" {
+		if user == "" {
+			return filter, problem.MissingUser()
+		}
+		filter.Assignee = user
+	}
+	if options.Query != "" {
+		parsed, err := taskquery.ParseIn(options.Query, taskquery.Env{User: user, Now: time.Now()})
+		var parseErr *taskquery.ParseError
+		if errors.As(err, &parseErr) {
+			return filter, problem.InvalidParameter("query", parseErr.Error()).With("position", parseErr.Position)
+		}
+		if err != nil {
+			return filter, err
+		}
+		filter.Query = parsed
+	}
+	if filter.Sort != "" && filter.Sort != data.SortSmart {
+		return filter, problem.InvalidParameter("sort", "must be 'smart'")
+	}
+	if err := fieldsError(options.Fields); err != nil {
+		return filter, err
+	}
+	return filter, nil
 }
 
 // GetTask retrieves a task by its ID.
 // It expects the ID to be passed as a parameter in the request URL.
-// If the ID is not a valid ObjectID, it returns a 400 Bad Request.
+// The optional "fields" query parameter, a comma separated list such as "id,title,status",
+// restricts the fields returned.
+// If the ID is not a valid ObjectID or a field is unknown, it returns a 400 Bad Request.
 // If the task is not found, it returns a 404 Not Found.
 // If any other error occurs, it returns a 500 Internal Server Error.
 // The retrieved task is returned as a JSON response with status 200 OK.
 func GetTask(c *gin.Context) {
+	fields := parseFields(c.Query("fields"))
+	if !checkFields(c, fields) {
+		return
+	}
 
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {  // If the ID is not a valid ObjectID, return a 400 Bad Request
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID format"})
+		c.Error(problem.InvalidParameter("id", "must be a valid ObjectID"))
 		return
 	}
-	task, err := data.GetTaskByID(objID)
+	task, err := data.GetTaskByID(c.Request.Context(), objID, fields...)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, task)
+	if len(fields) == 0 {
+		c.JSON(http.StatusOK, task)
+		return
+	}
+	selected, err := selectTaskFields(*task, fields)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, selected)
 }
 
 
 // CreateTask handles the creation of a new task.
-// It expects a JSON payload containing the task details.
+// It expects a JSON payload containing the task details, validated by the binding tags of
+// models.TaskIdLess; the due date, if any, can't be in the past.
 // If the payload is valid, it creates a new task and returns the created task as JSON.
 // If the payload is invalid or any error occurs during the creation process, it returns an appropriate error message as JSON.
 func CreateTask(c *gin.Context) {
 	var newTask models.TaskIdLess
 
-	if err := c.ShouldBindJSON(&newTask); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	if !bindJSON(c, &newTask) {
 		return
 	}
-	if newTask.Description == ""{
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Description can't be empty"})
-		return
-	}
-	if newTask.Title == ""{
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Title can't be empty"})
+	if !validateField(c, "due_date", newTask.DueDate, "notpast") {
 		return
 	}
 	
-	createdTask, err := data.AddNewTask(newTask)
+	user, _ := currentUser(c)
+	createdTask, err := data.AddNewTask(c.Request.Context(), newTask, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, createdTask)
 }
 
 // UpdateTask updates a task with the provided ID.
-// It expects a JSON payload containing the updated task information.
+// It expects a JSON payload containing the updated task information, validated like on creation
+// except that the due date may be in the past.
 // The ID of the task to be updated is extracted from the request parameters.
 //
-// If the JSON payload cannot be parsed or the ID is not a valid ObjectID, it returns a 400 Bad Request response.
+// If the JSON payload cannot be parsed or is invalid, or the ID is not a valid ObjectID, it returns a 400 Bad Request response.
 // If the task with the provided ID does not exist, it returns a 404 Not Found response.
 // If an error occurs during the update operation, it returns a 500 Internal Server Error response.
 // The updated task is returned in the response body if the update is successful.
 func UpdateTask(c *gin.Context) {
 
 	var newTask models.TaskIdLess
-	if err := c.ShouldBindJSON(&newTask); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	if !bindJSON(c, &newTask) {
 		return
 	}
 
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil{ // If the ID is not a valid ObjectID, return a 400 Bad Request
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID format"})
+		c.Error(problem.InvalidParameter("id", "must be a valid ObjectID"))
 		return
 	}
-	res, err := data.UpdateTaskById(objID, newTask)
+	user, _ := currentUser(c)
+	res, err := data.UpdateTaskById(c.Request.Context(), objID, newTask, user)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -125,21 +214,35 @@ func UpdateTask(c *gin.Context) {
 // - If the task is not found, it returns a 404 Not Found.
 // - If there is an internal server error, it returns a 500 Internal Server Error.
 // - If the task is deleted successfully, it returns a 200 OK with a success message.
+// The task can be brought back with RestoreTask.
 func DeleteTask(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil{ // If the ID is not a valid ObjectID, return a 400 Bad Request
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID format"})
+		c.Error(problem.InvalidParameter("id", "must be a valid ObjectID"))
 		return
 	}
-	_, err = data.DeleteTaskByID(objID)
+	_, err = data.DeleteTaskByID(c.Request.Context(), objID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "deleted successfully"})
-}
\ No newline at end of file
+}
+
+// RestoreTask brings back a deleted task, with its comments, attachments and time entries.
+// If the ID is not a valid ObjectID, it returns a 400 Bad Request.
+// If there is no deleted task with this ID, it returns a 404 Not Found.
+// The restored task is returned with status 200 OK.
+func RestoreTask(c *gin.Context) {
+	id, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	task, err := data.RestoreTask(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
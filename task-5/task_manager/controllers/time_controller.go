@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StartTimer starts a timer for the user given in the X-User-ID header on the task with the provided ID.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the task is not found, it returns a 404 Not Found.
+// If the user already has a running timer, on any task, it returns a 409 Conflict.
+// The running time entry is returned with status 201 Created.
+func StartTimer(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, err := data.StartTimer(c.Request.Context(), taskID, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// StopTimer stops the running timer of the user given in the X-User-ID header on the task with the provided ID.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the user has no running timer on the task, it returns a 404 Not Found.
+// The stopped time entry is returned with status 200 OK.
+func StopTimer(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, err := data.StopTimer(c.Request.Context(), taskID, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetTimeEntries lists the time entries of the task with the provided ID, most recent first.
+func GetTimeEntries(c *gin.Context) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := data.GetTimeEntries(c.Request.Context(), taskID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// CreateTimeEntry records time spent on the task with the provided ID by the user given in the X-User-ID header.
+// It expects a JSON payload such as {"start": "...", "end": "...", "note": "..."} with RFC 3339 times.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the payload is invalid or the end is not after the start, it returns a 400 Bad Request.
+// If the task is not found, it returns a 404 Not Found.
+// The created time entry is returned with status 201 Created.
+func CreateTimeEntry(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return
+	}
+	input, ok := bindTimeEntry(c)
+	if !ok {
+		return
+	}
+
+	entry, err := data.AddTimeEntry(c.Request.Context(), taskID, user, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateTimeEntry changes the period and note of a stopped time entry. Only its owner may change it.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the caller is not the owner, it returns a 403 Forbidden.
+// If the entry is not found or still running, it returns a 404 Not Found.
+// The updated time entry is returned with status 200 OK.
+func UpdateTimeEntry(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, entryID, ok := timeEntryIDs(c)
+	if !ok {
+		return
+	}
+	input, ok := bindTimeEntry(c)
+	if !ok {
+		return
+	}
+
+	entry, err := data.UpdateTimeEntry(c.Request.Context(), taskID, entryID, user, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteTimeEntry deletes a time entry. Only its owner may delete it.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the caller is not the owner, it returns a 403 Forbidden.
+// If the entry is not found, it returns a 404 Not Found.
+func DeleteTimeEntry(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	taskID, entryID, ok := timeEntryIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := data.DeleteTimeEntry(c.Request.Context(), taskID, entryID, user); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted successfully"})
+}
+
+// GetTimeTotals returns the time spent, summed per task, per user or per day.
+// Query parameters:
+//   - group_by: "task", "user" or "day" (the default)
+//   - task_id, user: only count the entries of that task or user
+//   - from, to: only count the entries starting in [from, to), as YYYY-MM-DD dates or RFC 3339 times
+func GetTimeTotals(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", data.GroupByDay)
+	if groupBy != data.GroupByTask && groupBy != data.GroupByUser && groupBy != data.GroupByDay {
+		c.Error(problem.InvalidParameter("group_by", "must be one of 'task', 'user' or 'day'"))
+		return
+	}
+
+	filter := data.TimeFilter{User: c.Query("user")}
+	if raw := c.Query("task_id"); raw != "" {
+		taskID, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.Error(problem.InvalidParameter("task_id", "must be a valid ObjectID"))
+			return
+		}
+		filter.TaskID = taskID
+	}
+	var ok bool
+	if filter.From, filter.To, ok = dateRange(c); !ok {
+		return
+	}
+
+	totals, err := data.GetTimeTotals(c.Request.Context(), filter, groupBy)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, totals)
+}
+
+// bindTimeEntry reads and validates a manual time entry payload.
+// It reports a 400 Bad Request and returns false if the payload is invalid.
+func bindTimeEntry(c *gin.Context) (models.TimeEntryInput, bool) {
+	var input models.TimeEntryInput
+	if !bindJSON(c, &input) {
+		return input, false
+	}
+	var invalid []problem.FieldError
+	if input.Start.IsZero() {
+		invalid = append(invalid, problem.FieldError{Field: "start", Message: "is required"})
+	}
+	if input.End.IsZero() {
+		invalid = append(invalid, problem.FieldError{Field: "end", Message: "is required"})
+	} else if !input.End.After(input.Start) {
+		invalid = append(invalid, problem.FieldError{Field: "end", Message: "must be after start"})
+	}
+	if len(invalid) > 0 {
+		c.Error(problem.Validation(invalid...))
+		return input, false
+	}
+	return input, true
+}
+
+// timeEntryIDs parses the task and time entry IDs of the URL.
+// It reports a 400 Bad Request and returns false if either is invalid.
+func timeEntryIDs(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	taskID, ok := objectIDParam(c, "id")
+	if !ok {
+		return taskID, primitive.NilObjectID, false
+	}
+	entryID, ok := objectIDParam(c, "entryId")
+	return taskID, entryID, ok
+}
+
+// dateRange parses the optional "from" and "to" query parameters, given either as
+// YYYY-MM-DD dates or as RFC 3339 times. It reports a 400 Bad Request and returns false
+// if either is malformed or if from is not before to.
+func dateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var bounds [2]time.Time
+	for i, name := range []string{"from", "to"} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			parsed, err = time.Parse(time.RFC3339, raw)
+		}
+		if err != nil {
+			c.Error(problem.InvalidParameter(name, "must be a YYYY-MM-DD date or an RFC 3339 time"))
+			return time.Time{}, time.Time{}, false
+		}
+		bounds[i] = parsed
+	}
+	if !bounds[0].IsZero() && !bounds[1].IsZero() && !bounds[0].Before(bounds[1]) {
+		c.Error(problem.InvalidParameter("from", "must be before to"))
+		return time.Time{}, time.Time{}, false
+	}
+	return bounds[0], bounds[1], true
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHeader is the request header identifying the user making the request.
+// The API has no authentication yet, so the header is trusted as is.
+const UserHeader = "X-User-ID"
+
+// currentUser returns the ID of the user making the request and whether one was provided.
+func currentUser(c *gin.Context) (string, bool) {
+	user := strings.TrimSpace(c.GetHeader(UserHeader))
+	return user, user != ""
+}
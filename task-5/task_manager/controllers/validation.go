@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"task_manager/models"
+	"task_manager/problem"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// validate is the validator gin runs on bound payloads, extended with the validators below.
+var validate = binding.Validator.Engine().(*validator.Validate)
+
+func init() {
+	// Report fields by their JSON name, the one clients know.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validate.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	validate.RegisterValidation("status", func(fl validator.FieldLevel) bool {
+		return models.ValidStatus(fl.Field().String())
+	})
+	validate.RegisterValidation("priority", func(fl validator.FieldLevel) bool {
+		return models.ValidPriority(fl.Field().String())
+	})
+	// notpast accepts the zero time and any time from the start of the current day, in UTC,
+	// so a due date given as a bare date for today is still accepted.
+	validate.RegisterValidation("notpast", func(fl validator.FieldLevel) bool {
+		due, ok := fl.Field().Interface().(time.Time)
+		if !ok {
+			return false
+		}
+		return due.IsZero() || !due.Before(time.Now().UTC().Truncate(24*time.Hour))
+	})
+}
+
+// validateField checks value against the validator tag, reporting failures under field.
+// It reports a validation_failed problem and returns false if the value is invalid.
+func validateField(c *gin.Context, field string, value interface{}, tag string) bool {
+	err := validate.Var(value, tag)
+	if err == nil {
+		return true
+	}
+	var invalid []problem.FieldError
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		for _, fieldErr := range errs {
+			invalid = append(invalid, problem.FieldError{Field: field, Message: validationMessage(fieldErr)})
+		}
+	}
+	c.Error(problem.Validation(invalid...))
+	return false
+}
+
+// ValidateTask checks a task payload decoded by another API than the JSON one, which validates
+// payloads as it binds them, against the same rules: the binding tags of models.TaskIdLess and,
+// when creating is true, a due date which is not in the past.
+// It returns a validation_failed problem listing every invalid field, or nil if the task is valid.
+func ValidateTask(task models.TaskIdLess, creating bool) error {
+	var invalid []problem.FieldError
+	if errs, ok := validate.Struct(task).(validator.ValidationErrors); ok {
+		invalid = fieldErrors(errs)
+	}
+	if creating {
+		if errs, ok := validate.Var(task.DueDate, "notpast").(validator.ValidationErrors); ok {
+			for _, fieldErr := range errs {
+				invalid = append(invalid, problem.FieldError{Field: "due_date", Message: validationMessage(fieldErr)})
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return problem.Validation(invalid...)
+	}
+	return nil
+}
+
+// fieldErrors translates the failures reported by the validator into one error per field.
+func fieldErrors(errs validator.ValidationErrors) []problem.FieldError {
+	invalid := make([]problem.FieldError, 0, len(errs))
+	for _, fieldErr := range errs {
+		// The namespace starts with the name of the bound struct, e.g. "TaskIdLess.tags[2]".
+		field := fieldErr.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		invalid = append(invalid, problem.FieldError{Field: field, Message: validationMessage(fieldErr)})
+	}
+	return invalid
+}
+
+// validationMessage describes a failed validation the way the other messages of the API read.
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "can't be empty"
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return "can't have more than " + fieldErr.Param() + " items"
+		}
+		return "can't be longer than " + fieldErr.Param() + " characters"
+	case "gte":
+		if fieldErr.Param() == "0" {
+			return "can't be negative"
+		}
+		return "must be at least " + fieldErr.Param()
+	case "status":
+		return "must be one of " + strings.Join(models.Statuses, ", ")
+	case "priority":
+		return "must be one of P0, P1, P2 or P3"
+	case "notpast":
+		return "can't be in the past"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetViews lists the views of the user given in the X-User-ID header, sorted by name.
+// If the header is missing, it returns a 401 Unauthorized.
+func GetViews(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+
+	views, err := data.GetViews(c.Request.Context(), user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// GetView returns a view of the user given in the X-User-ID header.
+// If the user has no view with the provided ID, it returns a 404 Not Found.
+func GetView(c *gin.Context) {
+	user, id, ok := viewRequest(c)
+	if !ok {
+		return
+	}
+
+	view, err := data.GetView(c.Request.Context(), user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// CreateView saves a view for the user given in the X-User-ID header.
+// It expects a JSON payload with a name and the same options as the query parameters of GET /tasks,
+// for instance {"name": "My overdue", "assignee": "me", "query": "due<today -status:done", "sort": "smart"}.
+// If the header is missing, it returns a 401 Unauthorized.
+// If the payload is invalid, it returns a 400 Bad Request.
+// The created view is returned with status 201 Created.
+func CreateView(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return
+	}
+	input, ok := bindView(c)
+	if !ok {
+		return
+	}
+
+	view, err := data.AddView(c.Request.Context(), user, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, view)
+}
+
+// UpdateView replaces the name and options of a view of the user given in the X-User-ID header.
+// If the payload is invalid, it returns a 400 Bad Request.
+// If the user has no view with the provided ID, it returns a 404 Not Found.
+func UpdateView(c *gin.Context) {
+	user, id, ok := viewRequest(c)
+	if !ok {
+		return
+	}
+	input, ok := bindView(c)
+	if !ok {
+		return
+	}
+
+	view, err := data.UpdateView(c.Request.Context(), user, id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView deletes a view of the user given in the X-User-ID header.
+// If the user has no view with the provided ID, it returns a 404 Not Found.
+func DeleteView(c *gin.Context) {
+	user, id, ok := viewRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := data.DeleteView(c.Request.Context(), user, id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted successfully"})
+}
+
+// GetViewTasks evaluates a view of the user given in the X-User-ID header and returns its tasks,
+// exactly as GET /tasks would with the options of the view. Relative dates such as "today"
+// are resolved at evaluation time, so views like "This week" stay current.
+// If the user has no view with the provided ID, it returns a 404 Not Found.
+func GetViewTasks(c *gin.Context) {
+	user, id, ok := viewRequest(c)
+	if !ok {
+		return
+	}
+	view, err := data.GetView(c.Request.Context(), user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter, ok := buildTaskFilter(c, view.TaskListOptions)
+	if !ok {
+		return
+	}
+
+	tasks, err := data.GetAllTasks(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	selected, err := selectFields(tasks, view.Fields)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, selected)
+}
+
+// viewRequest reads the user and the view ID of a request on a single view.
+// It reports a 401 Unauthorized or a 400 Bad Request and returns false if either is missing or invalid.
+func viewRequest(c *gin.Context) (string, primitive.ObjectID, bool) {
+	user, ok := currentUser(c)
+	if !ok {
+		c.Error(problem.MissingUser())
+		return "", primitive.NilObjectID, false
+	}
+	id, ok := objectIDParam(c, "id")
+	if !ok {
+		return "", primitive.NilObjectID, false
+	}
+	return user, id, true
+}
+
+// bindView reads and validates a view payload. The options are checked the same way
+// GET /tasks checks its query parameters, so a saved view can always be evaluated.
+// It reports a problem and returns false if the payload is invalid.
+func bindView(c *gin.Context) (models.ViewInput, bool) {
+	var input models.ViewInput
+	if !bindJSON(c, &input) {
+		return input, false
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.Error(problem.Validation(problem.FieldError{Field: "name", Message: "can't be empty"}))
+		return input, false
+	}
+	input.Tags = data.ParseTags(strings.Join(input.Tags, ","))
+	if _, ok := buildTaskFilter(c, input.TaskListOptions); !ok {
+		return input, false
+	}
+	return input, true
+}
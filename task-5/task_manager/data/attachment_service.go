@@ -0,0 +1,197 @@
+package data
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"task_manager/models"
+	"task_manager/storage"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxAttachmentSize is the largest attachment accepted, in bytes.
+const MaxAttachmentSize = 10 << 20
+
+// allowedContentTypes lists the MIME types, as detected from the content, accepted as attachments.
+var allowedContentTypes = map[string]bool{
+	"application/pdf": true,
+	"application/zip": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+var blobStore storage.BlobStorage
+
+// blobLocks serialises, for each content key, the insertion of an attachment referencing the
+// content with the deletion of the content once unreferenced, so an upload of the same content
+// as an attachment being deleted can't end up referencing deleted content. The locks only hold
+// within this process: the attachments assume a single server, as does the local blob storage.
+var blobLocks = keyLocks{locks: make(map[string]*keyLock)}
+
+// keyLocks is a set of mutexes identified by keys, each kept only while in use.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	users int
+}
+
+// lock locks the mutex of key and returns the function unlocking it.
+func (l *keyLocks) lock(key string) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		l.locks[key] = lock
+	}
+	lock.users++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mu.Lock()
+		if lock.users--; lock.users == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// AddAttachment stores the content read from r as an attachment of the task with the specified ID.
+// The MIME type is sniffed from the first bytes of the content rather than trusted from the client,
+// and the content is streamed to the blob storage without being held in memory.
+// It returns ErrTaskNotFound if the task does not exist, ErrUnsupportedType if the content
+// type is not allowed, ErrAttachmentTooLarge if the content exceeds MaxAttachmentSize and
+// ErrUploadRaced if the same content was deleted meanwhile.
+// OperationTimeout bounds each database call but not the upload, which only ends with the request.
+func AddAttachment(ctx context.Context, taskID primitive.ObjectID, filename string, r io.Reader) (*models.Attachment, error) {
+	findCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if err := collection.FindOne(findCtx, live(bson.M{"_id": taskID})).Err(); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	buffered := bufio.NewReaderSize(r, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	contentType := strings.TrimSpace(strings.Split(http.DetectContentType(head), ";")[0])
+	if !allowedContentTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	key, size, err := blobStore.Put(buffered)
+	if errors.Is(err, storage.ErrTooLarge) {
+		return nil, ErrAttachmentTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := blobLocks.lock(key)
+	defer unlock()
+	// The content may have been deleted along with the last attachment referencing it since it
+	// was stored. The request body is gone by now, so the client is asked to upload it again;
+	// once locked, the content stays until this attachment is recorded.
+	content, err := blobStore.Open(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrUploadRaced
+	}
+	if err != nil {
+		return nil, err
+	}
+	content.Close()
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      key,
+		CreatedAt:   time.Now().UTC(),
+	}
+	insertCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if _, err := attachmentCollection.InsertOne(insertCtx, attachment); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetAttachments returns the metadata of every attachment of a task.
+func GetAttachments(ctx context.Context, taskID primitive.ObjectID) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	cursor, err := attachmentCollection.Find(ctx, live(bson.M{"task_id": taskID}))
+	if err != nil {
+		return nil, err
+	}
+	attachments := []models.Attachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// OpenAttachment returns the metadata of an attachment together with a reader over its content.
+// The caller must close the reader. It returns ErrAttachmentNotFound if the attachment does not exist.
+func OpenAttachment(ctx context.Context, taskID primitive.ObjectID, attachmentID primitive.ObjectID) (*models.Attachment, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	var attachment models.Attachment
+	err := attachmentCollection.FindOne(ctx, live(bson.M{"_id": attachmentID, "task_id": taskID})).Decode(&attachment)
+	if err != nil {
+		return nil, nil, notFound(err, ErrAttachmentNotFound)
+	}
+	content, err := blobStore.Open(attachment.SHA256)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attachment, content, nil
+}
+
+// DeleteAttachment deletes the metadata of an attachment and, if no other attachment shares the
+// same content, the content itself. It returns ErrAttachmentNotFound if the attachment does not exist.
+func DeleteAttachment(ctx context.Context, taskID primitive.ObjectID, attachmentID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	var attachment models.Attachment
+	err := attachmentCollection.FindOneAndDelete(ctx, live(bson.M{"_id": attachmentID, "task_id": taskID})).Decode(&attachment)
+	if err != nil {
+		return notFound(err, ErrAttachmentNotFound)
+	}
+	return releaseBlob(ctx, attachment.SHA256)
+}
+
+// releaseBlob deletes the content stored under key once no attachment references it anymore.
+func releaseBlob(ctx context.Context, key string) error {
+	unlock := blobLocks.lock(key)
+	defer unlock()
+	count, err := attachmentCollection.CountDocuments(ctx, bson.M{"sha256": key})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return blobStore.Delete(key)
+}
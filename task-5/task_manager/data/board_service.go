@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"task_manager/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// boardOrder sorts tasks by column, then by rank. The ID makes the order stable should two
+// tasks ever share a rank.
+var boardOrder = bson.D{{Key: "status", Value: 1}, {Key: "rank", Value: 1}, {Key: "_id", Value: 1}}
+
+// GetBoard returns the tasks grouped into one column per status, each column in rank order.
+// When columns is empty, every status in use gets a column, in alphabetical order. Otherwise
+// only the given statuses are returned, in the given order, including the empty ones.
+func GetBoard(ctx context.Context, columns []string) (*models.Board, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{})
+	if len(columns) > 0 {
+		filter["status"] = bson.M{"$in": columns}
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(boardOrder))
+	if err != nil {
+		return nil, err
+	}
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	byStatus := make(map[string][]models.Task)
+	for _, task := range tasks {
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+	}
+	if len(columns) == 0 {
+		for status := range byStatus {
+			columns = append(columns, status)
+		}
+		sort.Strings(columns)
+	}
+
+	board := models.Board{Columns: make([]models.BoardColumn, 0, len(columns))}
+	for _, status := range columns {
+		column := models.BoardColumn{Status: status, Tasks: byStatus[status]}
+		if column.Tasks == nil {
+			column.Tasks = []models.Task{}
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	return &board, nil
+}
+
+// MoveTask moves a task to the given column and position of the board.
+// Only the moved task gets a new rank, computed between the ranks of its new neighbours;
+// its status and rank are changed together by a single update.
+// It returns ErrTaskNotFound if the task does not exist and ErrInvalidMove if a
+// neighbour does not exist or is not in the target column.
+func MoveTask(ctx context.Context, id primitive.ObjectID, move models.TaskMove) (*models.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	move.Status = models.NormalizeStatus(move.Status)
+	var previous models.Task
+	if err := collection.FindOne(ctx, live(bson.M{"_id": id})).Decode(&previous); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	lower, upper := "", ""
+	var err error
+	switch {
+	case move.AfterID != nil && move.BeforeID != nil:
+		if lower, err = neighbourRank(ctx, *move.AfterID, move.Status); err != nil {
+			return nil, err
+		}
+		if upper, err = neighbourRank(ctx, *move.BeforeID, move.Status); err != nil {
+			return nil, err
+		}
+	case move.AfterID != nil:
+		if lower, err = neighbourRank(ctx, *move.AfterID, move.Status); err != nil {
+			return nil, err
+		}
+		if upper, err = adjacentRank(ctx, move.Status, id, bson.M{"$gt": lower}, 1); err != nil {
+			return nil, err
+		}
+	case move.BeforeID != nil:
+		if upper, err = neighbourRank(ctx, *move.BeforeID, move.Status); err != nil {
+			return nil, err
+		}
+		if lower, err = adjacentRank(ctx, move.Status, id, bson.M{"$lt": upper}, -1); err != nil {
+			return nil, err
+		}
+	default:
+		if lower, err = adjacentRank(ctx, move.Status, id, bson.M{"$exists": true}, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	rank, err := rankBetween(lower, upper)
+	if err == errRankOrder {
+		return nil, ErrInvalidMove
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"status": move.Status, "rank": rank}}
+	setCompletion(update, previous.Status, move.Status)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var moved models.Task
+	if err := collection.FindOneAndUpdate(ctx, live(bson.M{"_id": id}), update, opts).Decode(&moved); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+	return &moved, nil
+}
+
+// neighbourRank returns the rank of the task with the given ID, which must be in the status column.
+// A neighbour without a rank is rejected, as the empty rank would stand for an open bound.
+func neighbourRank(ctx context.Context, id primitive.ObjectID, status string) (string, error) {
+	var task models.Task
+	err := collection.FindOne(ctx, live(bson.M{"_id": id, "status": status})).Decode(&task)
+	if err == mongo.ErrNoDocuments || (err == nil && task.Rank == "") {
+		return "", ErrInvalidMove
+	}
+	return task.Rank, err
+}
+
+// adjacentRank returns the rank of the first task of the status column, other than the task
+// being moved, whose rank matches the condition, looking upwards when direction is 1 and
+// downwards when it is -1. It returns an empty rank when there is no such task. Deleted tasks
+// count, so they still have a rank of their own when they are restored.
+func adjacentRank(ctx context.Context, status string, moving primitive.ObjectID, condition bson.M, direction int) (string, error) {
+	filter := bson.M{"status": status, "rank": condition, "_id": bson.M{"$ne": moving}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "rank", Value: direction}})
+	var task models.Task
+	err := collection.FindOne(ctx, filter, opts).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	return task.Rank, err
+}
+
+// unranked matches the tasks created before board ranks existed, which have none.
+var unranked = bson.M{"$in": bson.A{nil, ""}}
+
+// backfillRanks gives a rank to the tasks without one, placing them at the bottom of their column
+// in creation order, so they can be moved and used as neighbours like the others. Each update is
+// conditional, so servers starting together don't rank a task twice.
+func backfillRanks(ctx context.Context) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetProjection(bson.M{"status": 1})
+	cursor, err := collection.Find(ctx, bson.M{"rank": unranked}, opts)
+	if err != nil {
+		return err
+	}
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+
+	last := make(map[string]string)
+	for _, task := range tasks {
+		lower, ok := last[task.Status]
+		if !ok {
+			if lower, err = adjacentRank(ctx, task.Status, task.ID, bson.M{"$gt": ""}, -1); err != nil {
+				return err
+			}
+		}
+		rank, err := rankBetween(lower, "")
+		if err != nil {
+			return err
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": task.ID, "rank": unranked}, bson.M{"$set": bson.M{"rank": rank}}); err != nil {
+			return err
+		}
+		last[task.Status] = rank
+	}
+	return nil
+}
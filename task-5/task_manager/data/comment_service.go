@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddComment adds a comment written by author to the task with the specified ID.
+// Users @mentioned in the comment are notified.
+// It returns ErrTaskNotFound if the task does not exist.
+func AddComment(ctx context.Context, taskID primitive.ObjectID, author string, body string) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if err := collection.FindOne(ctx, live(bson.M{"_id": taskID})).Err(); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	now := time.Now().UTC()
+	comment := models.Comment{
+		ID:        primitive.NewObjectID(),
+		TaskID:    taskID,
+		Author:    author,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := commentCollection.InsertOne(ctx, comment); err != nil {
+		return nil, err
+	}
+	notifyMentions(ctx, ParseMentions(body), author, taskID, &comment.ID)
+	return &comment, nil
+}
+
+// GetComments returns a page of the comments of a task, oldest first.
+// Pages are numbered from 1; pages past the last one are empty.
+func GetComments(ctx context.Context, taskID primitive.ObjectID, page int, limit int) (*models.CommentPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"task_id": taskID})
+	total, err := commentCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	comments := []models.Comment{}
+	// Checking the page against the count first keeps the skip below it, so it can't overflow.
+	if pages := (total + int64(limit) - 1) / int64(limit); int64(page-1) >= pages {
+		return &models.CommentPage{Comments: comments, Page: page, Limit: limit, Total: total}, nil
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(page-1) * int64(limit)).
+		SetLimit(int64(limit))
+	cursor, err := commentCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return &models.CommentPage{Comments: comments, Page: page, Limit: limit, Total: total}, nil
+}
+
+// UpdateComment replaces the body of a comment of the given task.
+// It returns ErrCommentNotFound if the comment does not exist
+// and ErrNotAuthor if editor is not the author of the comment.
+// Users newly @mentioned by the edit are notified.
+func UpdateComment(ctx context.Context, taskID primitive.ObjectID, commentID primitive.ObjectID, editor string, body string) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"_id": commentID, "task_id": taskID})
+	var existing models.Comment
+	if err := commentCollection.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return nil, notFound(err, ErrCommentNotFound)
+	}
+	if existing.Author != editor {
+		return nil, ErrNotAuthor
+	}
+
+	filter["author"] = editor
+	update := bson.M{"$set": bson.M{"body": body, "updated_at": time.Now().UTC()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Comment
+	if err := commentCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		return nil, notFound(err, ErrCommentNotFound)
+	}
+	notifyMentions(ctx, newMentions(existing.Body, body), editor, taskID, &commentID)
+	return &updated, nil
+}
+
+// DeleteComment deletes a comment of the given task.
+// It returns ErrCommentNotFound if the comment does not exist
+// and ErrNotAuthor if user is not the author of the comment.
+func DeleteComment(ctx context.Context, taskID primitive.ObjectID, commentID primitive.ObjectID, user string) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"_id": commentID, "task_id": taskID})
+	var existing models.Comment
+	if err := commentCollection.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return notFound(err, ErrCommentNotFound)
+	}
+	if existing.Author != user {
+		return ErrNotAuthor
+	}
+
+	filter["author"] = user
+	result, err := commentCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Kind classifies domain errors so callers can react to them without knowing each error.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindInvalid
+	KindTooLarge
+	KindUnsupported
+	KindTimeout
+)
+
+// Error is an error of the data layer which is safe to report to clients.
+// Code is a stable identifier of the error, Message a human readable description.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errors returned by the data layer. Any other error is internal and its text must not reach clients.
+var (
+	ErrTaskNotFound         = &Error{KindNotFound, "task_not_found", "task not found"}
+	ErrCommentNotFound      = &Error{KindNotFound, "comment_not_found", "comment not found"}
+	ErrAttachmentNotFound   = &Error{KindNotFound, "attachment_not_found", "attachment not found"}
+	ErrNotificationNotFound = &Error{KindNotFound, "notification_not_found", "notification not found"}
+	ErrTimeEntryNotFound    = &Error{KindNotFound, "time_entry_not_found", "time entry not found"}
+	ErrNoRunningTimer       = &Error{KindNotFound, "no_running_timer", "no running timer on this task"}
+	ErrViewNotFound         = &Error{KindNotFound, "view_not_found", "view not found"}
+
+	ErrNotAuthor = &Error{KindForbidden, "not_author", "only the author can change this comment"}
+	ErrNotOwner  = &Error{KindForbidden, "not_owner", "only the owner can change this time entry"}
+
+	ErrTimerRunning   = &Error{KindConflict, "timer_running", "a timer is already running for this user"}
+	ErrInvalidMove    = &Error{KindConflict, "invalid_move", "neighbour tasks must belong to the target column"}
+	ErrUploadRaced    = &Error{KindConflict, "upload_raced", "the same content was being deleted during the upload, retry it"}
+	ErrNoChangeStream = &Error{KindConflict, "no_change_stream", "watching tasks needs MongoDB to run as a replica set"}
+
+	ErrAttachmentTooLarge = &Error{KindTooLarge, "attachment_too_large", "file exceeds the maximum allowed size"}
+	ErrUnsupportedType    = &Error{KindUnsupported, "unsupported_type", "file type is not allowed"}
+
+	ErrTimeout = &Error{KindTimeout, "timeout", "the database did not answer in time"}
+)
+
+// IsTimeout reports whether err was caused by an operation running past its deadline.
+// Such errors come from the driver, so they are recognized here rather than returned as ErrTimeout.
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err)
+}
+
+// notFound replaces mongo.ErrNoDocuments with the not found error of the resource, leaving other errors as they are.
+func notFound(err error, resource *Error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return resource
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IdempotencyKeyTTL is how long idempotency keys are remembered.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyLease is how long a request may be handled before a retry with the same key
+// is considered abandoned and takes the key over.
+const IdempotencyLease = time.Minute
+
+// ReserveIdempotencyKey records that the request identified by id, with the given hash, is being
+// handled. If the key is already known, nothing is recorded and the existing record is returned
+// instead; keys older than IdempotencyKeyTTL which MongoDB has not removed yet are replaced.
+// A key still being handled past its IdempotencyLease, whose request likely died with its process,
+// is taken over by a retry of the same request.
+func ReserveIdempotencyKey(ctx context.Context, id string, requestHash string) (*models.IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	now := time.Now().UTC()
+	record := models.IdempotencyRecord{ID: id, RequestHash: requestHash, CreatedAt: now, LockedUntil: now.Add(IdempotencyLease)}
+	for {
+		_, err := idempotencyCollection.InsertOne(ctx, record)
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		var existing models.IdempotencyRecord
+		err = idempotencyCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&existing)
+		if err == mongo.ErrNoDocuments {
+			continue // expired in the meantime
+		}
+		if err != nil {
+			return nil, err
+		}
+		if time.Since(existing.CreatedAt) >= IdempotencyKeyTTL {
+			filter := bson.M{"_id": id, "created_at": existing.CreatedAt}
+			if _, err := idempotencyCollection.DeleteOne(ctx, filter); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if existing.Completed || existing.RequestHash != requestHash || now.Before(existing.LockedUntil) {
+			return &existing, nil
+		}
+		// The lease is over: take the key over, unless another retry just did
+		filter := bson.M{"_id": id, "completed": false, "locked_until": existing.LockedUntil}
+		if existing.LockedUntil.IsZero() {
+			filter["locked_until"] = bson.M{"$exists": false}
+		}
+		result, err := idempotencyCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"locked_until": record.LockedUntil}})
+		if err != nil {
+			return nil, err
+		}
+		if result.ModifiedCount == 1 {
+			return nil, nil
+		}
+	}
+}
+
+// CompleteIdempotencyKey stores the response of the request identified by id.
+func CompleteIdempotencyKey(ctx context.Context, id string, status int, contentType string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	update := bson.M{"$set": bson.M{"completed": true, "status": status, "content_type": contentType, "body": body}}
+	_, err := idempotencyCollection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// ReleaseIdempotencyKey forgets a request which could not be handled, so it can be retried.
+func ReleaseIdempotencyKey(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	_, err := idempotencyCollection.DeleteOne(ctx, bson.M{"_id": id, "completed": false})
+	return err
+}
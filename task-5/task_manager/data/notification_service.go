@@ -0,0 +1,115 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mentionPattern matches @username mentions. The @ must not follow a word character,
+// so e-mail addresses such as "dev@example.com" are not taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+// ParseMentions returns the distinct usernames mentioned with @username in text, in order of appearance.
+func ParseMentions(text string) []string {
+	seen := make(map[string]bool)
+	mentions := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		mentions = append(mentions, username)
+	}
+	return mentions
+}
+
+// newMentions returns the users mentioned in text but not already mentioned in previous,
+// so editing a text only notifies the users added by the edit.
+func newMentions(previous string, text string) []string {
+	already := make(map[string]bool)
+	for _, username := range ParseMentions(previous) {
+		already[username] = true
+	}
+	var added []string
+	for _, username := range ParseMentions(text) {
+		if !already[username] {
+			added = append(added, username)
+		}
+	}
+	return added
+}
+
+// notifyMentions sends a mention notification to every user in users except the actor.
+// Notifications are best effort: a failure is logged and does not fail the operation that mentioned the users.
+func notifyMentions(ctx context.Context, users []string, actor string, taskID primitive.ObjectID, commentID *primitive.ObjectID) {
+	var notifications []interface{}
+	now := time.Now().UTC()
+	for _, user := range users {
+		if user == actor {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			ID:        primitive.NewObjectID(),
+			User:      user,
+			Kind:      models.NotificationMention,
+			TaskID:    taskID,
+			CommentID: commentID,
+			Actor:     actor,
+			CreatedAt: now,
+		})
+	}
+	if len(notifications) == 0 {
+		return
+	}
+	if _, err := notificationCollection.InsertMany(ctx, notifications); err != nil {
+		slog.WarnContext(ctx, "failed to send mention notifications", "task_id", taskID.Hex(), "error", err.Error())
+	}
+}
+
+// GetNotifications returns the notifications of a user, newest first.
+// When unreadOnly is true, notifications already marked as read are left out.
+func GetNotifications(ctx context.Context, user string, unreadOnly bool) ([]models.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := bson.M{"user": user}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := notificationCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	notifications := []models.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification of the given user as read.
+// It returns ErrNotificationNotFound if the user has no such notification.
+func MarkNotificationRead(ctx context.Context, user string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	result, err := notificationCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "user": user},
+		bson.M{"$set": bson.M{"read": true}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
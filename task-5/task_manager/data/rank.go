@@ -0,0 +1,63 @@
+package data
+
+import (
+	"errors"
+	"strings"
+)
+
+// rankDigits is the alphabet of board ranks. Ranks are compared as plain strings,
+// so the digits must be listed in increasing byte order.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankDigits)
+
+// errRankOrder is returned by rankBetween when the lower bound is not below the upper bound.
+var errRankOrder = errors.New("lower rank must sort before upper rank")
+
+// rankDigit returns the value of the i-th digit of rank.
+func rankDigit(rank string, i int) int {
+	return strings.IndexByte(rankDigits, rank[i])
+}
+
+// rankBetween returns a rank sorting strictly between lower and upper, where an empty
+// string stands for an open bound. This lets a task be moved by rewriting its own rank
+// only, without renumbering the rest of the column.
+//
+// Generated ranks never end with the digit '0', which guarantees there is always room
+// to insert a new rank before an existing one. When upper is open the rank is only
+// incremented, so appending many tasks to a column keeps ranks short.
+func rankBetween(lower string, upper string) (string, error) {
+	if upper != "" && lower >= upper {
+		return "", errRankOrder
+	}
+	if lower == "" && upper == "" {
+		return string(rankDigits[rankBase/2]), nil
+	}
+
+	var rank []byte
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(lower) {
+			low = rankDigit(lower, i)
+		}
+		high := rankBase
+		if upper != "" {
+			high = rankDigit(upper, i)
+		}
+
+		if low == high {
+			rank = append(rank, rankDigits[low])
+			continue
+		}
+		if high-low > 1 {
+			next := (low + high) / 2
+			if upper == "" {
+				next = low + 1
+			}
+			return string(append(rank, rankDigits[next])), nil
+		}
+		// The digits are adjacent: keep the lower one and continue with an open upper bound.
+		rank = append(rank, rankDigits[low])
+		upper = ""
+	}
+}
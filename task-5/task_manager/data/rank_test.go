@@ -0,0 +1,98 @@
+package data
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		lower, upper string
+		want         string
+	}{
+		{"", "", "i"},
+		{"", "i", "9"},
+		{"i", "", "j"},
+		{"a", "c", "b"},
+		{"a", "b", "a1"},
+		{"a", "a1", "a01"},
+		{"az", "b", "az1"},
+		{"z", "", "z1"},
+		{"", "1", "01"},
+	}
+	for _, test := range tests {
+		got, err := rankBetween(test.lower, test.upper)
+		if err != nil {
+			t.Errorf("rankBetween(%q, %q): unexpected error %v", test.lower, test.upper, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("rankBetween(%q, %q) = %q, want %q", test.lower, test.upper, got, test.want)
+		}
+	}
+}
+
+func TestRankBetweenRejectsUnorderedBounds(t *testing.T) {
+	for _, bounds := range [][2]string{{"b", "a"}, {"a", "a"}} {
+		if _, err := rankBetween(bounds[0], bounds[1]); !errors.Is(err, errRankOrder) {
+			t.Errorf("rankBetween(%q, %q): got error %v, want %v", bounds[0], bounds[1], err, errRankOrder)
+		}
+	}
+}
+
+// checkRank fails the test unless rank sorts strictly between lower and upper, where an empty
+// bound is open, and leaves room before it.
+func checkRank(t *testing.T, rank string, lower string, upper string) {
+	t.Helper()
+	if rank <= lower || (upper != "" && rank >= upper) {
+		t.Fatalf("rank %q does not sort between %q and %q", rank, lower, upper)
+	}
+	if strings.HasSuffix(rank, "0") {
+		t.Fatalf("rank %q ends with '0'", rank)
+	}
+}
+
+func TestRankBetweenRepeatedHeadInserts(t *testing.T) {
+	first := ""
+	for i := 0; i < 1000; i++ {
+		rank, err := rankBetween("", first)
+		if err != nil {
+			t.Fatalf("insert %d before %q: %v", i+1, first, err)
+		}
+		checkRank(t, rank, "", first)
+		first = rank
+	}
+}
+
+func TestRankBetweenRepeatedTailInserts(t *testing.T) {
+	last := ""
+	for i := 0; i < 1000; i++ {
+		rank, err := rankBetween(last, "")
+		if err != nil {
+			t.Fatalf("insert %d after %q: %v", i+1, last, err)
+		}
+		checkRank(t, rank, last, "")
+		last = rank
+	}
+	// Appending only increments the last digit, so ranks grow by one digit every rankBase inserts.
+	if len(last) > 1000/(rankBase-1)+2 {
+		t.Errorf("got a rank of %d digits after 1000 appends", len(last))
+	}
+}
+
+func TestRankBetweenRepeatedInsertsBetweenNeighbours(t *testing.T) {
+	lower, upper := "a", "b"
+	for i := 0; i < 200; i++ {
+		rank, err := rankBetween(lower, upper)
+		if err != nil {
+			t.Fatalf("insert %d between %q and %q: %v", i+1, lower, upper, err)
+		}
+		checkRank(t, rank, lower, upper)
+		if i%2 == 0 {
+			lower = rank
+		} else {
+			upper = rank
+		}
+	}
+}
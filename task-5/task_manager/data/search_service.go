@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"task_manager/models"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// titleWeight is how much more a match in the title counts than a match in the description.
+	titleWeight = 10
+	// snippetRadius is the number of characters kept on each side of the first match of a snippet.
+	snippetRadius = 60
+)
+
+// searchQuery is a parsed full-text query, following the MongoDB $text syntax:
+// quoted phrases must all appear, terms prefixed by "-" must not appear and any
+// of the remaining terms is enough.
+type searchQuery struct {
+	terms    []string
+	phrases  []string
+	excluded []string
+}
+
+// parseSearchQuery splits a full-text query into terms, phrases and negated terms, lowercased.
+func parseSearchQuery(q string) searchQuery {
+	var query searchQuery
+	q = strings.ToLower(q)
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				end = len(q) - 1
+			}
+			if phrase := strings.TrimSpace(q[1 : end+1]); phrase != "" {
+				query.phrases = append(query.phrases, phrase)
+			}
+			q = q[min(end+2, len(q)):]
+			continue
+		}
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end < 0 {
+			end = len(q)
+		}
+		word := q[:end]
+		q = q[end:]
+		if strings.HasPrefix(word, "-") {
+			if word = strings.TrimLeft(word, "-"); word != "" {
+				query.excluded = append(query.excluded, word)
+			}
+			continue
+		}
+		query.terms = append(query.terms, word)
+	}
+	return query
+}
+
+// empty reports whether the query has nothing that could match a task.
+func (q searchQuery) empty() bool {
+	return len(q.terms) == 0 && len(q.phrases) == 0
+}
+
+// highlight returns a snippet of text around its first match of the query, with every
+// match wrapped in <mark> tags and the rest of the text HTML escaped. It returns an
+// empty string when nothing matches.
+func (q searchQuery) highlight(text string) string {
+	needles := append(append([]string{}, q.phrases...), q.terms...)
+	type span struct{ start, end int }
+	var spans []span
+	for _, needle := range needles {
+		for offset := 0; offset < len(text); {
+			start, end := indexFold(text[offset:], needle)
+			if start < 0 {
+				break
+			}
+			spans = append(spans, span{offset + start, offset + end})
+			offset += end
+		}
+	}
+	if len(spans) == 0 {
+		return ""
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	from := max(spans[0].start-snippetRadius, 0)
+	to := min(spans[0].end+snippetRadius, len(text))
+	for from > 0 && !isRuneStart(text, from) {
+		from--
+	}
+	for to < len(text) && !isRuneStart(text, to) {
+		to++
+	}
+
+	var snippet strings.Builder
+	if from > 0 {
+		snippet.WriteString("…")
+	}
+	cursor := from
+	for _, s := range spans {
+		if s.start < cursor || s.end > to {
+			continue
+		}
+		snippet.WriteString(html.EscapeString(text[cursor:s.start]))
+		snippet.WriteString("<mark>" + html.EscapeString(text[s.start:s.end]) + "</mark>")
+		cursor = s.end
+	}
+	snippet.WriteString(html.EscapeString(text[cursor:to]))
+	if to < len(text) {
+		snippet.WriteString("…")
+	}
+	return snippet.String()
+}
+
+// indexFold returns the byte offsets in text of the start and the end of the first match of
+// needle, in lower case, ignoring the case of text, or -1, -1 if there is none. Offsets are those
+// of text itself, as lowering its case may change the length of its characters.
+func indexFold(text string, needle string) (int, int) {
+	for start := 0; start < len(text); {
+		end, i := start, 0
+		for i < len(needle) && end < len(text) {
+			t, tSize := utf8.DecodeRuneInString(text[end:])
+			n, nSize := utf8.DecodeRuneInString(needle[i:])
+			if unicode.ToLower(t) != n {
+				break
+			}
+			end += tSize
+			i += nSize
+		}
+		if i == len(needle) {
+			return start, end
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		start += size
+	}
+	return -1, -1
+}
+
+func isRuneStart(text string, i int) bool {
+	return text[i]&0xC0 != 0x80
+}
+
+// result wraps a task into a search result with its highlights.
+func (q searchQuery) result(task models.Task, score float64) models.SearchResult {
+	highlights := make(map[string]string)
+	if snippet := q.highlight(task.Title); snippet != "" {
+		highlights["title"] = snippet
+	}
+	if snippet := q.highlight(task.Description); snippet != "" {
+		highlights["description"] = snippet
+	}
+	return models.SearchResult{Task: task, Score: score, Highlights: highlights}
+}
+
+// SearchTasks runs a full-text search over task titles and descriptions and returns at most
+// limit results, most relevant first. The query supports "quoted phrases" and -negated terms.
+// The search uses the MongoDB text index created by Connect.
+func SearchTasks(ctx context.Context, q string, limit int) ([]models.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, AggregationTimeout)
+	defer cancel()
+	query := parseSearchQuery(q)
+	if query.empty() {
+		return []models.SearchResult{}, nil
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, live(bson.M{"$text": bson.M{"$search": q}}), opts)
+	if err != nil {
+		return nil, err
+	}
+	var matches []struct {
+		models.Task `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &matches); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, query.result(match.Task, match.Score))
+	}
+	return results, nil
+}
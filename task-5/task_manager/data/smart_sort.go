@@ -0,0 +1,78 @@
+package data
+
+import (
+	"sort"
+	"task_manager/models"
+	"time"
+)
+
+// priorityWeights gives the part of the smart score contributed by each priority.
+var priorityWeights = map[string]float64{
+	models.PriorityP0: 100,
+	models.PriorityP1: 60,
+	models.PriorityP2: 30,
+	models.PriorityP3: 10,
+}
+
+const (
+	// dueSoonWindow is how far ahead a due date starts raising the score of a task.
+	dueSoonWindow = 14 * 24 * time.Hour
+	// dueSoonWeight is the score added to a task due right now, decreasing linearly over dueSoonWindow.
+	dueSoonWeight = 40.0
+	// overdueWeight is the score added to every overdue task.
+	overdueWeight = 50.0
+	// overduePerDay is added for each day a task is overdue, up to maxOverdueBonus.
+	overduePerDay   = 2.0
+	maxOverdueBonus = 30.0
+)
+
+// SmartScore ranks how urgently an open task needs attention at time now.
+// The score combines the priority of the task, how close its due date is and, once the
+// due date has passed, how long the task has been overdue. Higher means more urgent.
+// Tasks without a due date are scored on priority alone, and tasks created before priorities
+// existed, which have none, are scored as having models.DefaultPriority.
+func SmartScore(task models.Task, now time.Time) float64 {
+	score, ok := priorityWeights[task.Priority]
+	if !ok {
+		score = priorityWeights[models.DefaultPriority]
+	}
+	if task.DueDate.IsZero() {
+		return score
+	}
+
+	untilDue := task.DueDate.Sub(now)
+	if untilDue < 0 {
+		overdueDays := -untilDue.Hours() / 24
+		bonus := overdueDays * overduePerDay
+		if bonus > maxOverdueBonus {
+			bonus = maxOverdueBonus
+		}
+		return score + overdueWeight + bonus
+	}
+	if untilDue < dueSoonWindow {
+		score += dueSoonWeight * (1 - float64(untilDue)/float64(dueSoonWindow))
+	}
+	return score
+}
+
+// sortSmart orders tasks for a daily stand-up: open tasks first, by decreasing SmartScore,
+// then finished tasks. Ties are broken by the earliest due date, tasks without one coming last.
+func sortSmart(tasks []models.Task, now time.Time) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		doneI, doneJ := models.IsDoneStatus(tasks[i].Status), models.IsDoneStatus(tasks[j].Status)
+		if doneI != doneJ {
+			return !doneI
+		}
+		if !doneI {
+			scoreI, scoreJ := SmartScore(tasks[i], now), SmartScore(tasks[j], now)
+			if scoreI != scoreJ {
+				return scoreI > scoreJ
+			}
+		}
+		dueI, dueJ := tasks[i].DueDate, tasks[j].DueDate
+		if dueI.IsZero() || dueJ.IsZero() {
+			return !dueI.IsZero() && dueJ.IsZero()
+		}
+		return dueI.Before(dueJ)
+	})
+}
@@ -0,0 +1,71 @@
+package data
+
+import (
+	"math"
+	"task_manager/models"
+	"testing"
+	"time"
+)
+
+func TestSmartScore(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		name     string
+		priority string
+		due      time.Time
+		want     float64
+	}{
+		{"P0 without due date", models.PriorityP0, time.Time{}, 100},
+		{"P1 without due date", models.PriorityP1, time.Time{}, 60},
+		{"P2 without due date", models.PriorityP2, time.Time{}, 30},
+		{"P3 without due date", models.PriorityP3, time.Time{}, 10},
+		{"no priority counts as the default", "", time.Time{}, 30},
+		{"due beyond the window", models.PriorityP2, now.Add(dueSoonWindow + day), 30},
+		{"due at the edge of the window", models.PriorityP2, now.Add(dueSoonWindow), 30},
+		{"due halfway through the window", models.PriorityP2, now.Add(dueSoonWindow / 2), 50},
+		{"due right now", models.PriorityP2, now, 70},
+		{"overdue by a day", models.PriorityP2, now.Add(-day), 82},
+		{"overdue by ten days", models.PriorityP2, now.Add(-10 * day), 100},
+		{"overdue bonus capped", models.PriorityP2, now.Add(-15 * day), 110},
+		{"overdue bonus stays capped", models.PriorityP2, now.Add(-365 * day), 110},
+		{"overdue without priority", "", now.Add(-day), 82},
+	}
+	for _, test := range tests {
+		task := models.Task{Priority: test.priority, DueDate: test.due}
+		if got := SmartScore(task, now); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got score %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSortSmart(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{Title: "done, due early", Status: "done", Priority: models.PriorityP0, DueDate: now.AddDate(0, 0, -30)},
+		{Title: "P3 without due date", Status: "todo", Priority: models.PriorityP3},
+		{Title: "legacy without due date", Status: "todo"},
+		{Title: "P2 due in a month", Status: "todo", Priority: models.PriorityP2, DueDate: now.AddDate(0, 1, 0)},
+		{Title: "P2 due in two months", Status: "todo", Priority: models.PriorityP2, DueDate: now.AddDate(0, 2, 0)},
+		{Title: "P2 overdue", Status: "in progress", Priority: models.PriorityP2, DueDate: now.AddDate(0, 0, -1)},
+		{Title: "done without due date", Status: "Completed", Priority: models.PriorityP0},
+		{Title: "done, due late", Status: "done", Priority: models.PriorityP3, DueDate: now.AddDate(0, 0, 30)},
+	}
+	sortSmart(tasks, now)
+
+	want := []string{
+		"P2 overdue",
+		"P2 due in a month",
+		"P2 due in two months",
+		"legacy without due date",
+		"P3 without due date",
+		"done, due early",
+		"done, due late",
+		"done without due date",
+	}
+	for i, task := range tasks {
+		if task.Title != want[i] {
+			t.Errorf("position %d: got %q, want %q", i, task.Title, want[i])
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// isoWeekFormat formats dates as ISO weeks, e.g. "2024-W35".
+const isoWeekFormat = "%G-W%V"
+
+// doneStatusExpr is the aggregation expression matching models.IsDoneStatus.
+var doneStatusExpr = bson.M{"$in": bson.A{bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$status"}}}, bson.A{"done", "completed"}}}
+
+// statsBucket is a group produced by one of the facets of the statistics pipeline.
+type statsBucket struct {
+	Key     string  `bson:"_id"`
+	Count   int     `bson:"count"`
+	Done    int     `bson:"done"`
+	CycleMs float64 `bson:"cycle_ms"`
+}
+
+// GetStats computes the task statistics over the period [from, to), optionally restricted to the
+// tasks carrying tag, which lets a tag stand for a project. Everything but the burndown is computed
+// by a single aggregation pipeline, and the burndown by a second one; tasks created before creation
+// times were recorded are dated by the timestamp embedded in their ObjectID.
+func GetStats(ctx context.Context, from time.Time, to time.Time, tag string) (*models.Stats, error) {
+	ctx, cancel := context.WithTimeout(ctx, AggregationTimeout)
+	defer cancel()
+	scope := live(bson.M{})
+	if tag != "" {
+		scope["tags"] = tag
+	}
+	createdInPeriod := bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}
+	completedInPeriod := bson.M{"completed_at": bson.M{"$gte": from, "$lt": to}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: scope}},
+		{{Key: "$addFields", Value: bson.M{
+			"created_at": bson.M{"$ifNull": bson.A{"$created_at", bson.M{"$toDate": "$_id"}}},
+			"done":       doneStatusExpr,
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"created": bson.A{
+				bson.M{"$match": createdInPeriod},
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"count": bson.M{"$sum": 1},
+					"done":  bson.M{"$sum": bson.M{"$cond": bson.A{"$done", 1, 0}}},
+				}},
+			},
+			"completed": bson.A{
+				bson.M{"$match": completedInPeriod},
+				bson.M{"$group": bson.M{
+					"_id":      nil,
+					"count":    bson.M{"$sum": 1},
+					"cycle_ms": bson.M{"$avg": bson.M{"$subtract": bson.A{"$completed_at", "$created_at"}}},
+				}},
+			},
+			"overdue": bson.A{
+				bson.M{"$match": bson.M{"done": false, "due_date": bson.M{"$gt": time.Time{}, "$lt": time.Now()}}},
+				bson.M{"$count": "count"},
+			},
+			"created_weekly": bson.A{
+				bson.M{"$match": createdInPeriod},
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$dateToString": bson.M{"format": isoWeekFormat, "date": "$created_at"}},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+			"completed_weekly": bson.A{
+				bson.M{"$match": completedInPeriod},
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$dateToString": bson.M{"format": isoWeekFormat, "date": "$completed_at"}},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var facets []struct {
+		Created         []statsBucket `bson:"created"`
+		Completed       []statsBucket `bson:"completed"`
+		Overdue         []statsBucket `bson:"overdue"`
+		CreatedWeekly   []statsBucket `bson:"created_weekly"`
+		CompletedWeekly []statsBucket `bson:"completed_weekly"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+	result := facets[0]
+
+	stats := models.Stats{
+		From: from.Format(time.RFC3339),
+		To:   to.Format(time.RFC3339),
+		Tag:  tag,
+	}
+	if len(result.Created) > 0 {
+		stats.Created = result.Created[0].Count
+		stats.CompletionRate = float64(result.Created[0].Done) / float64(result.Created[0].Count)
+	}
+	if len(result.Completed) > 0 {
+		stats.Completed = result.Completed[0].Count
+		stats.AverageCycleHours = result.Completed[0].CycleMs / float64(time.Hour/time.Millisecond)
+	}
+	if len(result.Overdue) > 0 {
+		stats.Overdue = result.Overdue[0].Count
+	}
+	stats.Weekly = mergeWeeks(result.CreatedWeekly, result.CompletedWeekly)
+
+	stats.Burndown, err = burndown(ctx, scope, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// mergeWeeks combines the weekly created and completed counts into a single series sorted by week.
+func mergeWeeks(created []statsBucket, completed []statsBucket) []models.WeeklyStats {
+	weeks := make(map[string]*models.WeeklyStats)
+	get := func(week string) *models.WeeklyStats {
+		if weeks[week] == nil {
+			weeks[week] = &models.WeeklyStats{Week: week}
+		}
+		return weeks[week]
+	}
+	for _, b := range created {
+		get(b.Key).Created = b.Count
+	}
+	for _, b := range completed {
+		get(b.Key).Completed = b.Count
+	}
+
+	series := make([]models.WeeklyStats, 0, len(weeks))
+	for _, week := range weeks {
+		series = append(series, *week)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Week < series[j].Week })
+	return series
+}
+
+// burndown returns, for each UTC day of [from, to), the number of tasks in scope that were
+// created by the end of the day and not completed by then. Done tasks with no recorded
+// completion time are treated as completed before the period. The pipeline counts the tasks
+// created and completed each day, those created earlier counting as created on the first day,
+// and the series is their running difference.
+func burndown(ctx context.Context, scope bson.M, from time.Time, to time.Time) ([]models.BurndownDay, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	perDay := func(date interface{}) bson.M {
+		return bson.M{"$group": bson.M{
+			"_id":   bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": date}},
+			"count": bson.M{"$sum": 1},
+		}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: scope}},
+		{{Key: "$project", Value: bson.M{
+			"created_at":   bson.M{"$ifNull": bson.A{"$created_at", bson.M{"$toDate": "$_id"}}},
+			"completed_at": 1,
+			"done":         doneStatusExpr,
+		}}},
+		{{Key: "$match", Value: bson.M{
+			"created_at": bson.M{"$lt": to},
+			"$or": bson.A{
+				bson.M{"done": false},
+				bson.M{"completed_at": bson.M{"$gte": from}},
+			},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"created": bson.A{perDay(bson.M{"$max": bson.A{"$created_at", start}})},
+			"completed": bson.A{
+				bson.M{"$match": bson.M{"done": true, "completed_at": bson.M{"$lt": to}}},
+				perDay("$completed_at"),
+			},
+		}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Created   []statsBucket `bson:"created"`
+		Completed []statsBucket `bson:"completed"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	change := make(map[string]int)
+	for _, result := range results {
+		for _, b := range result.Created {
+			change[b.Key] += b.Count
+		}
+		for _, b := range result.Completed {
+			change[b.Key] -= b.Count
+		}
+	}
+
+	series := []models.BurndownDay{}
+	remaining := 0
+	for day := start; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		remaining += change[date]
+		series = append(series, models.BurndownDay{Date: date, Remaining: remaining})
+	}
+	return series, nil
+}
+
+// CountOpenTasks counts the tasks whose status is not a done status and, among them, those whose
+// due date has passed.
+func CountOpenTasks(ctx context.Context) (int, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{"$expr": bson.M{"$not": bson.A{doneStatusExpr}}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":  nil,
+			"open": bson.M{"$sum": 1},
+			"overdue": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$gt": bson.A{"$due_date", time.Time{}}},
+					bson.M{"$lt": bson.A{"$due_date", time.Now()}},
+				}},
+				1, 0,
+			}}},
+		}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	var counts []struct {
+		Open    int `bson:"open"`
+		Overdue int `bson:"overdue"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return 0, 0, err
+	}
+	if len(counts) == 0 {
+		return 0, 0, nil
+	}
+	return counts[0].Open, counts[0].Overdue, nil
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"task_manager/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultTagColor is the color reported for tags that were never given one.
+const DefaultTagColor = "#9e9e9e"
+
+// normalizeTags lowercases and trims the given tags, dropping empty and duplicate entries.
+// It always returns a non-nil slice so tasks are stored with an empty array rather than null.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// ParseTags splits a comma separated list of tags, as received in a query string, and normalizes it.
+func ParseTags(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return normalizeTags(strings.Split(raw, ","))
+}
+
+// AddTagsToTask adds the given tags to the task with the specified ID.
+// Tags already present on the task are left untouched.
+// It returns the updated task, or ErrTaskNotFound if the task does not exist.
+func AddTagsToTask(ctx context.Context, id primitive.ObjectID, tags []string) (*models.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	update := bson.M{"$addToSet": bson.M{"tags": bson.M{"$each": normalizeTags(tags)}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Task
+	err := collection.FindOneAndUpdate(ctx, live(bson.M{"_id": id}), update, opts).Decode(&updated)
+	if err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+	return &updated, nil
+}
+
+// RemoveTagFromTask removes a single tag from the task with the specified ID.
+// It returns the updated task, or ErrTaskNotFound if the task does not exist.
+func RemoveTagFromTask(ctx context.Context, id primitive.ObjectID, tag string) (*models.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	update := bson.M{"$pull": bson.M{"tags": strings.ToLower(strings.TrimSpace(tag))}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Task
+	err := collection.FindOneAndUpdate(ctx, live(bson.M{"_id": id}), update, opts).Decode(&updated)
+	if err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+	return &updated, nil
+}
+
+// GetTagCatalogue returns every known tag together with its color and the number of tasks using it.
+// Usage counts are aggregated from the tasks collection, colors come from the tags collection.
+// A tag that has a color but is not used by any task is still listed, with a count of zero.
+func GetTagCatalogue(ctx context.Context) ([]models.Tag, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{})}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	cursor, err = tagCollection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var stored []models.Tag
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	catalogue := make(map[string]*models.Tag)
+	for i := range stored {
+		catalogue[stored[i].Name] = &stored[i]
+	}
+	for _, c := range counts {
+		if tag, ok := catalogue[c.Name]; ok {
+			tag.Count = c.Count
+			continue
+		}
+		catalogue[c.Name] = &models.Tag{Name: c.Name, Color: DefaultTagColor, Count: c.Count}
+	}
+
+	tags := make([]models.Tag, 0, len(catalogue))
+	for _, tag := range catalogue {
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// SetTagColor stores the color of a tag in the catalogue, creating the catalogue entry if needed.
+func SetTagColor(ctx context.Context, name string, color string) (*models.Tag, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	name = strings.ToLower(strings.TrimSpace(name))
+	_, err := tagCollection.UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{"$set": bson.M{"color": color}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	count, err := collection.CountDocuments(ctx, live(bson.M{"tags": name}))
+	if err != nil {
+		return nil, err
+	}
+	return &models.Tag{Name: name, Color: color, Count: int(count)}, nil
+}
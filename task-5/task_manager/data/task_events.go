@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"task_manager/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of task changes reported to the watchers.
+const (
+	TaskCreated = "created"
+	TaskUpdated = "updated"
+	TaskDeleted = "deleted"
+)
+
+// TaskEvent is a change of a task: the task as it is after the change. A restored task is reported
+// as created, and a deleted task as it was when deleted.
+type TaskEvent struct {
+	Kind string
+	Task models.Task
+}
+
+// changeStreamUnsupported is the code of the MongoDB error opening a change stream on a server
+// which is not part of a replica set.
+const changeStreamUnsupported = 40573
+
+// changeEvent is the part of a change stream event on the tasks collection read by WatchTasks.
+type changeEvent struct {
+	OperationType string       `bson:"operationType"`
+	FullDocument  *models.Task `bson:"fullDocument"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+// WatchTasks returns a channel receiving every change of a task made from now on, until ctx is done.
+// Changes are read from a change stream on the tasks collection, so the changes made through any
+// server are seen; change streams need MongoDB to run as a replica set, and ErrNoChangeStream is
+// returned otherwise. The channel is closed when ctx is done, or earlier if the change stream
+// fails; the caller tells the two apart by checking ctx.Err().
+func WatchTasks(ctx context.Context) (<-chan TaskEvent, error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+	}}}}
+	stream, err := collection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == changeStreamUnsupported {
+		return nil, ErrNoChangeStream
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan TaskEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OperationTimeout)
+			defer cancel()
+			stream.Close(closeCtx)
+		}()
+		for stream.Next(ctx) {
+			var change changeEvent
+			if err := stream.Decode(&change); err != nil {
+				slog.WarnContext(ctx, "failed to decode a task change", "error", err.Error())
+				continue
+			}
+			event, ok := change.taskEvent()
+			if !ok {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "the task change stream failed", "error", err.Error())
+		}
+	}()
+	return events, nil
+}
+
+// taskEvent converts a change of the tasks collection to the event reported to the watchers.
+// It reports false for the changes the watchers don't see: changes to deleted tasks, and updates
+// of a task removed before its new version could be read.
+func (c changeEvent) taskEvent() (TaskEvent, bool) {
+	if c.OperationType == "delete" {
+		return TaskEvent{Kind: TaskDeleted, Task: models.Task{ID: c.DocumentKey.ID}}, true
+	}
+	if c.FullDocument == nil {
+		return TaskEvent{}, false
+	}
+	task := *c.FullDocument
+	switch {
+	case c.OperationType == "insert":
+		return TaskEvent{Kind: TaskCreated, Task: task}, true
+	case c.UpdateDescription.UpdatedFields["deleted_at"] != nil:
+		return TaskEvent{Kind: TaskDeleted, Task: task}, true
+	case task.DeletedAt != nil:
+		return TaskEvent{}, false
+	case slices.Contains(c.UpdateDescription.RemovedFields, "deleted_at"):
+		return TaskEvent{Kind: TaskCreated, Task: task}, true
+	default:
+		return TaskEvent{Kind: TaskUpdated, Task: task}, true
+	}
+}
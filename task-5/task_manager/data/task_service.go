@@ -2,51 +2,216 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"task_manager/metrics"
 	"task_manager/models"
+	"task_manager/storage"
+	"task_manager/taskquery"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 var client *mongo.Client
 var collection *mongo.Collection
+var tagCollection *mongo.Collection
+var commentCollection *mongo.Collection
+var attachmentCollection *mongo.Collection
+var notificationCollection *mongo.Collection
+var timeEntryCollection *mongo.Collection
+var viewCollection *mongo.Collection
+var idempotencyCollection *mongo.Collection
 
-// init initializes the MongoDB client and establishes a connection to the database.
+// TaskFilter holds the optional criteria used to narrow down the tasks returned by GetAllTasks.
+// Tags restricts the result to tasks carrying the given tags; when MatchAll is true a task
+// must carry every tag, otherwise carrying any one of them is enough.
+// Assignee, when set, restricts the result to tasks assigned to that user.
+// Sort selects the order of the result: SortSmart ranks open tasks by SmartScore,
+// anything else keeps the natural order of the collection.
+// Query, when set, is an advanced filter written in the task query language.
+// Fields, when set, lists the JSON names of the task fields to read; the other fields are left
+// out by a projection and keep their zero value.
+type TaskFilter struct {
+	Tags     []string
+	MatchAll bool
+	Assignee string
+	Sort     string
+	Query    *taskquery.Query
+	Fields   []string
+}
+
+// SortSmart is the TaskFilter sort ranking open tasks by priority and due date.
+const SortSmart = "smart"
+
+// Connect initializes the MongoDB client and establishes a connection to the database.
 // It sets up the necessary configurations and checks if the connection is successful.
 // The MongoDB connection URI is set to "mongodb://localhost:27017".
-// If any error occurs during the initialization process, it will be logged as fatal.
+// If any error occurs during the initialization process, it is returned and the data layer must not be used.
 // After a successful connection, the "taskManager" database and "tasks" collection are selected.
-func init(){
+// Attachment content is stored on the local filesystem under the directory named by the
+// ATTACHMENT_DIR environment variable, "attachments" by default.
+// Multikey indexes on the task tags and assignees are created so filtering on them doesn't scan the whole collection,
+// along with an index on status and rank used to read the board columns in order
+// and a text index on the title and description used by the full-text search.
+// Tasks created before board ranks existed are then given one, see backfillRanks.
+func Connect(ctx context.Context) error {
 	var err error
-	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	monitor := combineMonitors(metrics.MongoMonitor(), otelmongo.NewMonitor())
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor)
 
-	client, err = mongo.Connect(context.TODO(), clientOptions)
+	client, err = mongo.Connect(ctx, clientOptions)
 	if err != nil{
-		log.Fatal(err)
+		return err
 	}
 
-	err = client.Ping(context.TODO(), nil)
+	err = client.Ping(ctx, nil)
 	if err != nil{
-		log.Fatal("database not connected")
+		return fmt.Errorf("database not connected: %w", err)
 	}
-	fmt.Println("connected to database")
+	slog.InfoContext(ctx, "connected to database")
 	collection = client.Database("taskManager").Collection("tasks")
+	tagCollection = client.Database("taskManager").Collection("tags")
+	commentCollection = client.Database("taskManager").Collection("comments")
+	attachmentCollection = client.Database("taskManager").Collection("attachments")
+	notificationCollection = client.Database("taskManager").Collection("notifications")
+	timeEntryCollection = client.Database("taskManager").Collection("timeEntries")
+	viewCollection = client.Database("taskManager").Collection("views")
+	idempotencyCollection = client.Database("taskManager").Collection("idempotencyKeys")
+
+	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "assignees", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "rank", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("task_text").SetWeights(bson.M{"title": titleWeight, "description": 1}),
+		},
+	})
+	if err != nil{
+		return err
+	}
+	if err = backfillRanks(ctx); err != nil{
+		return err
+	}
+	_, err = commentCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}}})
+	if err != nil{
+		return err
+	}
+	_, err = attachmentCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}}},
+		{Keys: bson.D{{Key: "sha256", Value: 1}}},
+	})
+	if err != nil{
+		return err
+	}
+	_, err = notificationCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "user", Value: 1}, {Key: "created_at", Value: -1}}})
+	if err != nil{
+		return err
+	}
+	_, err = timeEntryCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "start", Value: -1}}},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "start", Value: 1}}},
+		// At most one running timer per user
+		{
+			Keys:    bson.D{{Key: "user", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"running": true}),
+		},
+	})
+	if err != nil{
+		return err
+	}
+	_, err = viewCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "name", Value: 1}}})
+	if err != nil{
+		return err
+	}
+	// Idempotency keys are removed by MongoDB once they expire
+	_, err = idempotencyCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(IdempotencyKeyTTL.Seconds())),
+	})
+	if err != nil{
+		return err
+	}
+
+	attachmentDir := os.Getenv("ATTACHMENT_DIR")
+	if attachmentDir == ""{
+		attachmentDir = "attachments"
+	}
+	blobStore, err = storage.NewLocalStorage(attachmentDir, MaxAttachmentSize)
+	return err
+}
+
+// combineMonitors returns a command monitor forwarding every event to each of monitors, since a
+// client takes a single one: the metrics time the commands and the tracing records a span for each.
+func combineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				m.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				m.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				m.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// Ping checks that the database answers, within OperationTimeout.
+func Ping(ctx context.Context) error {
+	if client == nil {
+		return errors.New("not connected to the database")
+	}
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	return client.Ping(ctx, nil)
+}
+
+// Disconnect closes the connections to the database once the operations in progress are done,
+// or when ctx expires.
+func Disconnect(ctx context.Context) error {
+	if client == nil {
+		return nil
+	}
+	return client.Disconnect(ctx)
 }
 
-// GetAllTasks retrieves all tasks from the database.
+
+
+// GetAllTasks retrieves the tasks matching the given filter from the database.
+// An empty filter returns every task.
 // It returns a slice of models.Task and an error if any.
-func GetAllTasks() ([]models.Task, error){
+func GetAllTasks(ctx context.Context, filter TaskFilter) ([]models.Task, error){
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
 	var tasks []models.Task
-	cursor, err := collection.Find(context.Background(), bson.D{})
+	fields := filter.Fields
+	if filter.Sort == SortSmart && len(fields) > 0 {
+		// The smart order needs these fields whatever the caller asked for
+		fields = append([]string{"status", "priority", "due_date"}, fields...)
+	}
+	cursor, err := collection.Find(ctx, filter.toBson(), options.Find().SetProjection(projection(fields)))
 	if err != nil{
 		return nil, err
 	}
-	defer cursor.Close(context.TODO())
+	defer cursor.Close(ctx)
 	
-	for cursor.Next(context.TODO()){
+	for cursor.Next(ctx){
 		var task models.Task
 		err := cursor.Decode(&task) 
 		if err != nil{
@@ -57,24 +222,114 @@ func GetAllTasks() ([]models.Task, error){
 	if err := cursor.Err(); err != nil{
 		return nil, err
 	}
+	if filter.Sort == SortSmart{
+		sortSmart(tasks, time.Now())
+	}
 	return tasks, nil
 }
 
+// EachTask calls fn on each task matching the filter, in the order GetAllTasks returns them,
+// stopping at the first error fn returns. Unless the tasks are sorted by SortSmart, which needs
+// them all, tasks are passed to fn as they are read rather than gathered first. OperationTimeout
+// then bounds each read from the database rather than the whole iteration, so a large result or
+// a slow fn, such as a send to a slow client, only ends with the deadline of ctx.
+func EachTask(ctx context.Context, filter TaskFilter, fn func(models.Task) error) error {
+	if filter.Sort == SortSmart {
+		tasks, err := GetAllTasks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	findCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	cursor, err := collection.Find(findCtx, filter.toBson(), options.Find().SetProjection(projection(filter.Fields)))
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OperationTimeout)
+		defer cancel()
+		cursor.Close(closeCtx)
+	}()
+	for {
+		// Fetching a batch gets its own deadline; the tasks of the batch then come from memory
+		nextCtx, cancel := context.WithTimeout(ctx, OperationTimeout)
+		next := cursor.Next(nextCtx)
+		cancel()
+		if !next {
+			break
+		}
+		var task models.Task
+		if err := cursor.Decode(&task); err != nil {
+			return err
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// GetTaskPage returns at most limit tasks matching the filter, skipping the first offset ones,
+// together with the number of tasks matching the filter. Unless the tasks are sorted by SortSmart,
+// which needs them all, only the page is read, the tasks being in creation order.
+func GetTaskPage(ctx context.Context, filter TaskFilter, offset int, limit int) ([]models.Task, int, error) {
+	if filter.Sort == SortSmart {
+		tasks, err := GetAllTasks(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if offset >= len(tasks) {
+			return []models.Task{}, len(tasks), nil
+		}
+		return tasks[offset:min(offset+limit, len(tasks))], len(tasks), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	query := filter.toBson()
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().
+		SetProjection(projection(filter.Fields)).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	tasks := []models.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, 0, err
+	}
+	return tasks, int(total), nil
+}
+
 // GetTaskByID retrieves a task from the database based on the provided ID.
 // It takes an `id` parameter of type `primitive.ObjectID` and returns a pointer to a `models.Task` and an error.
+// When fields are given, only those fields, named as in JSON, are read; the others keep their zero value.
 // If the task is found, it returns a pointer to the task and a `nil` error.
-// If no task is found, it returns `nil` and an error of type `mongo.ErrNoDocuments`.
+// If no task is found, it returns `nil` and ErrTaskNotFound.
 // If an error occurs during the retrieval process, it returns `nil` and the corresponding error.
-func GetTaskByID(id primitive.ObjectID) (*models.Task, error){
-	filter := bson.M{"_id": id}
+func GetTaskByID(ctx context.Context, id primitive.ObjectID, fields ...string) (*models.Task, error){
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"_id": id})
 
 	var task models.Task
-    err := collection.FindOne(context.TODO(), filter).Decode(&task)
+    err := collection.FindOne(ctx, filter, options.FindOne().SetProjection(projection(fields))).Decode(&task)
     if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return nil, err // No document found
-        }
-        return nil, err
+        return nil, notFound(err, ErrTaskNotFound)
     }
     return &task, nil
 }
@@ -82,34 +337,72 @@ func GetTaskByID(id primitive.ObjectID) (*models.Task, error){
 
 // AddNewTask adds a new task to the collection.
 // It takes a task of type models.TaskIdLess as input and returns a pointer to the inserted task and an error, if any.
-// The task is inserted into the collection using the InsertOne method.
-// The inserted ID is retrieved and used to create a new task with the same properties as the input task.
+// A new ID is generated for the task, which is placed at the bottom of its status column on the board,
+// and the task is inserted into the collection using the InsertOne method.
 // The new task is then returned along with a nil error if the insertion is successful.
 // If there is an error during the insertion, nil is returned for the task and the error is returned.
-func AddNewTask(task models.TaskIdLess) (*models.Task, error) {
-    // Insert the task into the collection
-    result, err := collection.InsertOne(context.TODO(), task)
+// Users @mentioned in the description are notified on behalf of actor, the user creating the task,
+// who may be empty when the request named no user.
+func AddNewTask(ctx context.Context, task models.TaskIdLess, actor string) (*models.Task, error) {
+    ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+    defer cancel()
+    if task.Priority == "" {
+        task.Priority = models.DefaultPriority
+    }
+    task.Status = models.NormalizeStatus(task.Status)
+    // Place the task after the last task of its column
+    lastRank, err := adjacentRank(ctx, task.Status, primitive.NilObjectID, bson.M{"$exists": true}, -1)
     if err != nil {
         return nil, err
     }
-    // Get the inserted ID directly as a primitive.ObjectID
-    insertedID := result.InsertedID.(primitive.ObjectID)
-    // Create a new task with the inserted ID
+    rank, err := rankBetween(lastRank, "")
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now().UTC()
     insertedTask := models.Task{
-        ID:          insertedID,
+        ID:          primitive.NewObjectID(),
         Title:       task.Title,
         Description: task.Description,
         DueDate:     task.DueDate,
         Status:      task.Status,
+        Tags:        normalizeTags(task.Tags),
+        Assignees:   normalizeUsers(task.Assignees),
+        Priority:    task.Priority,
+        Estimate:    task.Estimate,
+        Rank:        rank,
+        CreatedAt:   now,
+    }
+    if models.IsDoneStatus(task.Status) {
+        insertedTask.CompletedAt = &now
     }
+    // Insert the task into the collection
+    if _, err := collection.InsertOne(ctx, insertedTask); err != nil {
+        return nil, err
+    }
+    notifyMentions(ctx, ParseMentions(task.Description), actor, insertedTask.ID, nil)
     return &insertedTask, nil
 }
 
 
 // UpdateTaskById updates a task in the database with the specified ID.
 // It takes the ID of the task to be updated and the updatedTask object containing the new values.
-// The function returns the updated task and an error, if any.
-func UpdateTaskById(id primitive.ObjectID, updatedTask models.TaskIdLess) (*models.Task, error) {
+// The function returns the updated task and an error, if any, ErrTaskNotFound when there is no such task.
+// Users newly @mentioned in the description are notified on behalf of actor, the user updating the task,
+// who may be empty when the request named no user.
+func UpdateTaskById(ctx context.Context, id primitive.ObjectID, updatedTask models.TaskIdLess, actor string) (*models.Task, error) {
+    ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+    defer cancel()
+    if updatedTask.Priority == "" {
+        updatedTask.Priority = models.DefaultPriority
+    }
+    updatedTask.Status = models.NormalizeStatus(updatedTask.Status)
+    var previous models.Task
+    if err := collection.FindOne(ctx, live(bson.M{"_id": id})).Decode(&previous); err != nil {
+        return nil, notFound(err, ErrTaskNotFound)
+    }
+
     // Create the update document
     update := bson.M{
         "$set": bson.M{
@@ -117,29 +410,168 @@ func UpdateTaskById(id primitive.ObjectID, updatedTask models.TaskIdLess) (*mode
             "description": updatedTask.Description,
             "due_date":    updatedTask.DueDate,
             "status":      updatedTask.Status,
+            "tags":        normalizeTags(updatedTask.Tags),
+            "assignees":   normalizeUsers(updatedTask.Assignees),
+            "priority":    updatedTask.Priority,
+            "estimate":    updatedTask.Estimate,
         },
     }
 
-    filter := bson.M{"_id": id}
+    setCompletion(update, previous.Status, updatedTask.Status)
+
+    filter := live(bson.M{"_id": id})
     opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
     var updated models.Task
-    err := collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&updated)
+    err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
     if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return nil, err
-        }
-        return nil, err
+        return nil, notFound(err, ErrTaskNotFound)
     }
+    notifyMentions(ctx, newMentions(previous.Description, updated.Description), actor, id, nil)
     return &updated, nil
 }
 
-// DeleteTaskByID deletes a task from the collection by its ID.
-// It takes the ID of the task as a parameter and returns a boolean value indicating whether the task was deleted successfully or not, along with any error that occurred during the deletion process.
-func DeleteTaskByID(id primitive.ObjectID) (bool, error){
+// Matches reports whether the task satisfies the filter, as the query document built by toBson would.
+func (f TaskFilter) Matches(task models.Task) bool {
+	if len(f.Tags) > 0 {
+		carried := 0
+		for _, tag := range f.Tags {
+			if slices.Contains(task.Tags, tag) {
+				carried++
+			}
+		}
+		if carried == 0 || (f.MatchAll && carried < len(f.Tags)) {
+			return false
+		}
+	}
+	if f.Assignee != "" && !slices.Contains(task.Assignees, f.Assignee) {
+		return false
+	}
+	return f.Query == nil || f.Query.Match(task)
+}
+
+// toBson builds the MongoDB query document corresponding to the filter.
+func (f TaskFilter) toBson() bson.M {
+	query := live(bson.M{})
+	if len(f.Tags) > 0 {
+		if f.MatchAll {
+			query["tags"] = bson.M{"$all": f.Tags}
+		} else {
+			query["tags"] = bson.M{"$in": f.Tags}
+		}
+	}
+	if f.Assignee != "" {
+		query["assignees"] = f.Assignee
+	}
+	if f.Query != nil {
+		query = bson.M{"$and": bson.A{query, f.Query.Filter()}}
+	}
+	return query
+}
+
+// setCompletion adds to update the changes of the completion time implied by a status change:
+// the completion time is set when a task becomes done and removed when it is reopened.
+func setCompletion(update bson.M, previousStatus string, status string) {
+	wasDone, isDone := models.IsDoneStatus(previousStatus), models.IsDoneStatus(status)
+	switch {
+	case isDone && !wasDone:
+		update["$set"].(bson.M)["completed_at"] = time.Now().UTC()
+	case wasDone && !isDone:
+		update["$unset"] = bson.M{"completed_at": ""}
+	}
+}
+
+// projection builds the MongoDB projection reading only the given task fields, named as in JSON.
+// The ID is always read. Without fields, the projection is nil and every field is read.
+func projection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+	p := bson.M{}
+	for _, field := range fields {
+		if name, ok := models.TaskFields[field]; ok {
+			p[name] = 1
+		}
+	}
+	return p
+}
+
+// normalizeUsers trims the given user IDs, dropping empty and duplicate entries.
+// It always returns a non-nil slice so tasks are stored with an empty array rather than null.
+func normalizeUsers(users []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, user := range users {
+		user = strings.TrimSpace(user)
+		if user == "" || seen[user] {
+			continue
+		}
+		seen[user] = true
+		result = append(result, user)
+	}
+	return result
+}
+
+// live adds to filter the condition excluding deleted records and returns it. Deleted tasks, and
+// the comments, attachments and time entries deleted with them, carry the time of their deletion
+// in deleted_at until they are restored.
+func live(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// taskRecords returns the collections holding the records of tasks, which are deleted and restored
+// with their task.
+func taskRecords() []*mongo.Collection {
+	return []*mongo.Collection{commentCollection, attachmentCollection, timeEntryCollection}
+}
+
+// DeleteTaskByID deletes a task by its ID, together with its comments, attachments and time entries.
+// The task and its records are kept, marked as deleted, so RestoreTask can bring them back.
+// The records are marked first: if that fails, the task is still there and the deletion can be retried.
+// It returns whether the task was deleted, and ErrTaskNotFound if there is no such task.
+func DeleteTaskByID(ctx context.Context, id primitive.ObjectID) (bool, error){
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if err := collection.FindOne(ctx, live(bson.M{"_id": id})).Err(); err != nil{
+		return false, notFound(err, ErrTaskNotFound)
+	}
 
-	_, err := collection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	deleted := bson.M{"$set": bson.M{"deleted_at": time.Now().UTC()}}
+	for _, records := range taskRecords() {
+		if _, err := records.UpdateMany(ctx, live(bson.M{"task_id": id}), deleted); err != nil{
+			return false, err
+		}
+	}
+	var task models.Task
+	err := collection.FindOneAndUpdate(ctx, live(bson.M{"_id": id}), deleted).Decode(&task)
 	if err != nil{
-		return false, err
+		return false, notFound(err, ErrTaskNotFound)
 	}
 	return true, nil
 }
+
+// RestoreTask brings back a deleted task together with the comments, attachments and time entries
+// deleted with it. The records are restored first, so a failure leaves the task deleted and the
+// restoration can be retried. It returns the restored task, or ErrTaskNotFound if there is no
+// deleted task with this ID.
+func RestoreTask(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	deleted := bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}}
+	if err := collection.FindOne(ctx, deleted).Err(); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	restored := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	for _, records := range taskRecords() {
+		if _, err := records.UpdateMany(ctx, bson.M{"task_id": id, "deleted_at": bson.M{"$exists": true}}, restored); err != nil {
+			return nil, err
+		}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var task models.Task
+	if err := collection.FindOneAndUpdate(ctx, deleted, restored, opts).Decode(&task); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+	return &task, nil
+}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"context"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Groupings accepted by GetTimeTotals.
+const (
+	GroupByTask = "task"
+	GroupByUser = "user"
+	GroupByDay  = "day"
+)
+
+// TimeFilter narrows down the time entries taken into account by GetTimeTotals.
+// Zero values mean no restriction.
+type TimeFilter struct {
+	TaskID primitive.ObjectID
+	User   string
+	From   time.Time
+	To     time.Time
+}
+
+// StartTimer starts a timer for the user on the task with the specified ID.
+// A unique index on the running entries of a user guarantees there is at most one running timer
+// per user, even when two requests race. It returns ErrTaskNotFound if the task does not exist
+// and ErrTimerRunning if the user already has a running timer.
+func StartTimer(ctx context.Context, taskID primitive.ObjectID, user string) (*models.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if err := collection.FindOne(ctx, live(bson.M{"_id": taskID})).Err(); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	entry := models.TimeEntry{
+		ID:      primitive.NewObjectID(),
+		TaskID:  taskID,
+		User:    user,
+		Start:   time.Now().UTC(),
+		Running: true,
+	}
+	if _, err := timeEntryCollection.InsertOne(ctx, entry); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrTimerRunning
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// StopTimer stops the running timer of the user on the task with the specified ID.
+// The end and duration are computed by the database in the same update, so stopping is atomic.
+// It returns ErrNoRunningTimer if the user has no running timer on the task.
+// A timer left running on a deleted task can still be stopped, so the user can start another one.
+func StopTimer(ctx context.Context, taskID primitive.ObjectID, user string) (*models.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	now := time.Now().UTC()
+	filter := bson.M{"task_id": taskID, "user": user, "running": true}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"end":              now,
+		"running":          false,
+		"duration_seconds": bson.M{"$toLong": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{now, "$start"}}, 1000}}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var entry models.TimeEntry
+	if err := timeEntryCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&entry); err != nil {
+		return nil, notFound(err, ErrNoRunningTimer)
+	}
+	return &entry, nil
+}
+
+// AddTimeEntry records time the user spent on a task without using the timer.
+// It returns ErrTaskNotFound if the task does not exist.
+func AddTimeEntry(ctx context.Context, taskID primitive.ObjectID, user string, input models.TimeEntryInput) (*models.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	if err := collection.FindOne(ctx, live(bson.M{"_id": taskID})).Err(); err != nil {
+		return nil, notFound(err, ErrTaskNotFound)
+	}
+
+	end := input.End.UTC()
+	entry := models.TimeEntry{
+		ID:       primitive.NewObjectID(),
+		TaskID:   taskID,
+		User:     user,
+		Start:    input.Start.UTC(),
+		End:      &end,
+		Duration: int64(input.End.Sub(input.Start).Seconds()),
+		Note:     input.Note,
+	}
+	if _, err := timeEntryCollection.InsertOne(ctx, entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetTimeEntries returns the time entries of a task, most recent first.
+func GetTimeEntries(ctx context.Context, taskID primitive.ObjectID) ([]models.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	opts := options.Find().SetSort(bson.D{{Key: "start", Value: -1}})
+	cursor, err := timeEntryCollection.Find(ctx, live(bson.M{"task_id": taskID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := []models.TimeEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateTimeEntry replaces the period and note of a stopped time entry of the given task.
+// It returns ErrTimeEntryNotFound if the entry does not exist or is still running
+// and ErrNotOwner if user is not the owner of the entry.
+func UpdateTimeEntry(ctx context.Context, taskID primitive.ObjectID, entryID primitive.ObjectID, user string, input models.TimeEntryInput) (*models.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"_id": entryID, "task_id": taskID, "running": false})
+	if err := checkTimeEntryOwner(ctx, filter, user); err != nil {
+		return nil, err
+	}
+
+	filter["user"] = user
+	update := bson.M{"$set": bson.M{
+		"start":            input.Start.UTC(),
+		"end":              input.End.UTC(),
+		"duration_seconds": int64(input.End.Sub(input.Start).Seconds()),
+		"note":             input.Note,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var entry models.TimeEntry
+	if err := timeEntryCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&entry); err != nil {
+		return nil, notFound(err, ErrTimeEntryNotFound)
+	}
+	return &entry, nil
+}
+
+// DeleteTimeEntry deletes a time entry of the given task, running or not.
+// It returns ErrTimeEntryNotFound if the entry does not exist
+// and ErrNotOwner if user is not the owner of the entry.
+func DeleteTimeEntry(ctx context.Context, taskID primitive.ObjectID, entryID primitive.ObjectID, user string) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	filter := live(bson.M{"_id": entryID, "task_id": taskID})
+	if err := checkTimeEntryOwner(ctx, filter, user); err != nil {
+		return err
+	}
+
+	filter["user"] = user
+	result, err := timeEntryCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+// checkTimeEntryOwner makes sure the time entry matching filter exists and belongs to user.
+func checkTimeEntryOwner(ctx context.Context, filter bson.M, user string) error {
+	var entry models.TimeEntry
+	if err := timeEntryCollection.FindOne(ctx, filter).Decode(&entry); err != nil {
+		return notFound(err, ErrTimeEntryNotFound)
+	}
+	if entry.User != user {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// GetTimeTotals sums the stopped time entries matching the filter, grouped by task, user or day.
+// Days are UTC calendar days of the entry start, formatted as YYYY-MM-DD; tasks are identified
+// by their hex ID. Groups are sorted by key. Running timers are not counted until they are stopped.
+func GetTimeTotals(ctx context.Context, filter TimeFilter, groupBy string) ([]models.TimeTotal, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	match := live(bson.M{"running": false})
+	if !filter.TaskID.IsZero() {
+		match["task_id"] = filter.TaskID
+	}
+	if filter.User != "" {
+		match["user"] = filter.User
+	}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		period := bson.M{}
+		if !filter.From.IsZero() {
+			period["$gte"] = filter.From
+		}
+		if !filter.To.IsZero() {
+			period["$lt"] = filter.To
+		}
+		match["start"] = period
+	}
+
+	var key interface{}
+	switch groupBy {
+	case GroupByTask:
+		key = bson.M{"$toString": "$task_id"}
+	case GroupByUser:
+		key = "$user"
+	default:
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$start"}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              key,
+			"duration_seconds": bson.M{"$sum": "$duration_seconds"},
+			"entries":          bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+	cursor, err := timeEntryCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	totals := []models.TimeTotal{}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
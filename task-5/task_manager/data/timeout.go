@@ -0,0 +1,46 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Deadlines applied to each call of the data layer, on top of the deadline of the request itself,
+// so a slow database can't hold a request forever: OperationTimeout for ordinary operations and
+// AggregationTimeout for the heavier statistics and search. ConfigureTimeouts reads them from
+// the environment.
+var (
+	OperationTimeout   = 5 * time.Second
+	AggregationTimeout = 15 * time.Second
+)
+
+// ConfigureTimeouts sets the deadlines of the data layer from the environment variables
+// MONGO_TIMEOUT and MONGO_AGGREGATION_TIMEOUT, holding Go durations such as "2s". Unset
+// variables keep the defaults of 5 and 15 seconds.
+func ConfigureTimeouts() error {
+	operation, err := durationFromEnv("MONGO_TIMEOUT", OperationTimeout)
+	if err != nil {
+		return err
+	}
+	aggregation, err := durationFromEnv("MONGO_AGGREGATION_TIMEOUT", AggregationTimeout)
+	if err != nil {
+		return err
+	}
+	OperationTimeout, AggregationTimeout = operation, aggregation
+	return nil
+}
+
+// durationFromEnv reads a positive duration from the environment variable name, or returns fallback
+// when it is not set.
+func durationFromEnv(name string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 5s, got %q", name, raw)
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"context"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddView saves a new view for owner.
+func AddView(ctx context.Context, owner string, input models.ViewInput) (*models.View, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	now := time.Now().UTC()
+	view := models.View{
+		ID:              primitive.NewObjectID(),
+		Owner:           owner,
+		Name:            input.Name,
+		TaskListOptions: input.TaskListOptions,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if _, err := viewCollection.InsertOne(ctx, view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// GetViews returns the views of owner, sorted by name.
+func GetViews(ctx context.Context, owner string) ([]models.View, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := viewCollection.Find(ctx, bson.M{"owner": owner}, opts)
+	if err != nil {
+		return nil, err
+	}
+	views := []models.View{}
+	if err := cursor.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// GetView returns a view of owner. It returns ErrViewNotFound if owner has no such view.
+func GetView(ctx context.Context, owner string, id primitive.ObjectID) (*models.View, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	var view models.View
+	if err := viewCollection.FindOne(ctx, bson.M{"_id": id, "owner": owner}).Decode(&view); err != nil {
+		return nil, notFound(err, ErrViewNotFound)
+	}
+	return &view, nil
+}
+
+// UpdateView replaces the name and options of a view of owner.
+// It returns ErrViewNotFound if owner has no such view.
+func UpdateView(ctx context.Context, owner string, id primitive.ObjectID, input models.ViewInput) (*models.View, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	var view models.View
+	if err := viewCollection.FindOne(ctx, bson.M{"_id": id, "owner": owner}).Decode(&view); err != nil {
+		return nil, notFound(err, ErrViewNotFound)
+	}
+	view.Name = input.Name
+	view.TaskListOptions = input.TaskListOptions
+	view.UpdatedAt = time.Now().UTC()
+
+	result, err := viewCollection.ReplaceOne(ctx, bson.M{"_id": id, "owner": owner}, view)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrViewNotFound
+	}
+	return &view, nil
+}
+
+// DeleteView deletes a view of owner. It returns ErrViewNotFound if owner has no such view.
+func DeleteView(ctx context.Context, owner string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+	result, err := viewCollection.DeleteOne(ctx, bson.M{"_id": id, "owner": owner})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrViewNotFound
+	}
+	return nil
+}
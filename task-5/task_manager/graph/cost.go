@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// maxCost bounds the number of database reads a request may cause, as estimated by cost before
+// the request runs. Every query and mutation field reads the database once, and so does each of
+// the fields below for every task it is asked for.
+const maxCost = 100
+
+// taskReads lists the fields of Task whose resolver reads the database.
+var taskReads = map[string]bool{"comments": true, "attachments": true, "time_entries": true, "time_spent_seconds": true}
+
+// boardTasks is the number of tasks a board is counted as holding: as it holds every task,
+// the fields reading the database can't be asked for on its tasks.
+const boardTasks = 1000
+
+// cost estimates the number of database reads of the operation named operationName in query,
+// or of its only operation. A query which can't be parsed costs nothing, as it won't run.
+func cost(query string, operationName string, variables map[string]interface{}) int {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0
+	}
+	estimate := costEstimate{fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			estimate.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	total := 0
+	for _, operation := range operations {
+		root := schema.QueryType()
+		if operation.Operation == ast.OperationTypeMutation {
+			root = schema.MutationType()
+		}
+		total = max(total, estimate.selections(operation.SelectionSet, root, make(map[string]bool)))
+	}
+	return total
+}
+
+// costEstimate holds what the estimate of an operation needs besides the operation itself.
+type costEstimate struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selections estimates the reads of the selections of an object of type parent. spreading holds
+// the fragments being spread, so a cycle, which the validation rejects anyway, ends the estimate.
+func (e costEstimate) selections(set *ast.SelectionSet, parent *graphql.Object, spreading map[string]bool) int {
+	if set == nil || parent == nil {
+		return 0
+	}
+	total := 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			total += e.field(selection, parent, spreading)
+		case *ast.InlineFragment:
+			total += e.selections(selection.SelectionSet, parent, spreading)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			if fragment, ok := e.fragments[name]; ok && !spreading[name] {
+				spreading[name] = true
+				total += e.selections(fragment.SelectionSet, parent, spreading)
+				delete(spreading, name)
+			}
+		}
+	}
+	return total
+}
+
+// field estimates the reads of a field of an object of type parent, and of its selections.
+func (e costEstimate) field(field *ast.Field, parent *graphql.Object, spreading map[string]bool) int {
+	definition, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		return 0
+	}
+	reads, times := 0, 1
+	switch {
+	case parent == schema.QueryType() || parent == schema.MutationType():
+		reads = 1
+		switch field.Name.Value {
+		case "tasks", "search":
+			times = e.intArg(field, definition, "limit")
+		case "board":
+			times = boardTasks
+		}
+	case parent.Name() == taskType.Name() && taskReads[field.Name.Value]:
+		reads = 1
+	}
+	child, _ := graphql.GetNamed(definition.Type).(*graphql.Object)
+	return reads + times*e.selections(field.SelectionSet, child, spreading)
+}
+
+// intArg returns the value of the limit argument name of field, as given in the request or by
+// a variable, or its default value. Values are kept between 0 and maxTaskLimit, as the resolvers
+// reject the others.
+func (e costEstimate) intArg(field *ast.Field, definition *graphql.FieldDefinition, name string) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				return min(max(n, 0), maxTaskLimit)
+			}
+		case *ast.Variable:
+			switch n := e.variables[value.Name.Value].(type) {
+			case float64:
+				return int(min(max(n, 0), maxTaskLimit))
+			case int:
+				return min(max(n, 0), maxTaskLimit)
+			}
+		}
+	}
+	for _, arg := range definition.Args {
+		if arg.Name() == name {
+			if n, ok := arg.DefaultValue.(int); ok {
+				return n
+			}
+		}
+	}
+	return 1
+}
@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/graphql-go/graphql"
+)
+
+// fieldError is a resolver error as reported to clients, with the stable code of the error and,
+// when there are any, the invalid fields in its extensions, like the problems of the REST API.
+type fieldError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *fieldError) Error() string {
+	return e.message
+}
+
+// Extensions is read by graphql-go to fill the "extensions" member of the error.
+func (e *fieldError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// resolve wraps a resolver so the errors it returns are reported like the REST API reports them.
+func resolve(fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := fn(p)
+		if err != nil {
+			return nil, translate(p.Context, err)
+		}
+		return result, nil
+	}
+}
+
+// translate converts an error of the data layer or of the validation to a fieldError.
+// Any other error is internal: it is logged and its text does not reach the client.
+func translate(ctx context.Context, err error) error {
+	var requestErr *problem.Error
+	if errors.As(err, &requestErr) {
+		extensions := map[string]interface{}{"code": requestErr.Code}
+		if len(requestErr.Fields) > 0 {
+			extensions["errors"] = requestErr.Fields
+		}
+		for name, value := range requestErr.Extensions {
+			extensions[name] = value
+		}
+		return &fieldError{message: requestErr.Detail, extensions: extensions}
+	}
+	if data.IsTimeout(err) {
+		err = data.ErrTimeout
+	}
+	var domainErr *data.Error
+	if errors.As(err, &domainErr) {
+		return &fieldError{message: domainErr.Message, extensions: map[string]interface{}{"code": domainErr.Code}}
+	}
+	slog.ErrorContext(ctx, "graphql resolver failed", "error", err.Error())
+	return &fieldError{message: "an unexpected error occurred", extensions: map[string]interface{}{"code": problem.CodeInternal}}
+}
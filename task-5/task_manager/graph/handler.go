@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"task_manager/controllers"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// codeTooCostly is the code of the error rejecting a request reading the database too often.
+const codeTooCostly = "query_too_costly"
+
+// userKey is the context key of the user making the request.
+type userKey struct{}
+
+// userFrom returns the user making the request, or "" if the request did not name one.
+func userFrom(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// request is the body of a GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler executes the GraphQL request in the JSON body, of the form
+// {"query": "...", "operationName": "...", "variables": {...}}, and answers with its result.
+// The user is given in the X-User-ID header, as for the REST API.
+// Errors raised while executing the request are reported in the "errors" member of a 200 OK
+// response, with the code of the error in their extensions; a body which is not a GraphQL
+// request returns a 400 Bad Request. A request estimated to read the database more than maxCost
+// times is rejected before it runs.
+func Handler(c *gin.Context) {
+	var body request
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(problem.InvalidBody("the request body is not a GraphQL request"))
+		return
+	}
+	if strings.TrimSpace(body.Query) == "" {
+		c.Error(problem.Validation(problem.FieldError{Field: "query", Message: "can't be empty"}))
+		return
+	}
+
+	if estimate := cost(body.Query, body.OperationName, body.Variables); estimate > maxCost {
+		c.JSON(http.StatusOK, graphql.Result{Errors: []gqlerrors.FormattedError{{
+			Message:    fmt.Sprintf("the request would read the database about %d times, more than the %d allowed; ask for fewer tasks or fewer fields of each", estimate, maxCost),
+			Extensions: map[string]interface{}{"code": codeTooCostly, "cost": estimate, "max_cost": maxCost},
+		}}})
+		return
+	}
+
+	user := strings.TrimSpace(c.GetHeader(controllers.UserHeader))
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  body.Query,
+		OperationName:  body.OperationName,
+		VariableValues: body.Variables,
+		Context:        context.WithValue(c.Request.Context(), userKey{}, user),
+	})
+	c.JSON(http.StatusOK, result)
+}
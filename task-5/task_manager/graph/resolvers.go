@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"task_manager/controllers"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+
+	"github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bounds of the list arguments, the same as the REST API's.
+const (
+	defaultTaskLimit    = 50
+	maxTaskLimit        = 100
+	defaultCommentLimit = 20
+	maxCommentLimit     = 100
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 100
+)
+
+func resolveTask(p graphql.ResolveParams) (interface{}, error) {
+	task, err := data.GetTaskByID(p.Context, p.Args["id"].(primitive.ObjectID))
+	if errors.Is(err, data.ErrTaskNotFound) {
+		return nil, nil
+	}
+	return task, err
+}
+
+// resolveTasks returns a page of the tasks matching the filter.
+func resolveTasks(p graphql.ResolveParams) (interface{}, error) {
+	offset, limit := p.Args["offset"].(int), p.Args["limit"].(int)
+	if offset < 0 {
+		return nil, problem.InvalidParameter("offset", "can't be negative")
+	}
+	if limit < 1 || limit > maxTaskLimit {
+		return nil, problem.InvalidParameter("limit", "must be between 1 and 100")
+	}
+	var options models.TaskListOptions
+	if input, ok := p.Args["filter"].(map[string]interface{}); ok {
+		options.Tags = stringsArg(input["tags"])
+		options.Match, _ = input["match"].(string)
+		options.Assignee, _ = input["assignee"].(string)
+		options.Query, _ = input["query"].(string)
+		options.Sort, _ = input["sort"].(string)
+	}
+	filter, err := controllers.NewTaskFilter(options, userFrom(p.Context))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, total, err := data.GetTaskPage(p.Context, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"tasks": tasks, "offset": offset, "limit": limit, "total": total}, nil
+}
+
+func resolveSearch(p graphql.ResolveParams) (interface{}, error) {
+	q := strings.TrimSpace(p.Args["q"].(string))
+	if q == "" {
+		return nil, problem.InvalidParameter("q", "can't be empty")
+	}
+	limit := p.Args["limit"].(int)
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, problem.InvalidParameter("limit", "must be between 1 and 100")
+	}
+	return data.SearchTasks(p.Context, q, limit)
+}
+
+func resolveBoard(p graphql.ResolveParams) (interface{}, error) {
+	return data.GetBoard(p.Context, stringsArg(p.Args["columns"]))
+}
+
+func resolveTagCatalogue(p graphql.ResolveParams) (interface{}, error) {
+	return data.GetTagCatalogue(p.Context)
+}
+
+func resolveStats(p graphql.ResolveParams) (interface{}, error) {
+	from, to, err := controllers.StatsPeriod(optionalTime(p.Args, "from"), optionalTime(p.Args, "to"))
+	if err != nil {
+		return nil, err
+	}
+	tag, _ := p.Args["tag"].(string)
+	return data.GetStats(p.Context, from, to, strings.ToLower(strings.TrimSpace(tag)))
+}
+
+func resolveTimeTotals(p graphql.ResolveParams) (interface{}, error) {
+	filter := data.TimeFilter{From: optionalTime(p.Args, "from"), To: optionalTime(p.Args, "to")}
+	filter.TaskID, _ = p.Args["task_id"].(primitive.ObjectID)
+	filter.User, _ = p.Args["user"].(string)
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, problem.InvalidParameter("from", "must be before to")
+	}
+	return data.GetTimeTotals(p.Context, filter, p.Args["group_by"].(string))
+}
+
+func resolveCreateTask(p graphql.ResolveParams) (interface{}, error) {
+	task := taskInput(p.Args["input"].(map[string]interface{}))
+	if err := controllers.ValidateTask(task, true); err != nil {
+		return nil, err
+	}
+	return data.AddNewTask(p.Context, task, userFrom(p.Context))
+}
+
+func resolveUpdateTask(p graphql.ResolveParams) (interface{}, error) {
+	task := taskInput(p.Args["input"].(map[string]interface{}))
+	if err := controllers.ValidateTask(task, false); err != nil {
+		return nil, err
+	}
+	return data.UpdateTaskById(p.Context, p.Args["id"].(primitive.ObjectID), task, userFrom(p.Context))
+}
+
+func resolveDeleteTask(p graphql.ResolveParams) (interface{}, error) {
+	return data.DeleteTaskByID(p.Context, p.Args["id"].(primitive.ObjectID))
+}
+
+func resolveRestoreTask(p graphql.ResolveParams) (interface{}, error) {
+	return data.RestoreTask(p.Context, p.Args["id"].(primitive.ObjectID))
+}
+
+func resolveComments(p graphql.ResolveParams) (interface{}, error) {
+	page, limit := p.Args["page"].(int), p.Args["limit"].(int)
+	if page < 1 {
+		return nil, problem.InvalidParameter("page", "must be a positive integer")
+	}
+	if limit < 1 || limit > maxCommentLimit {
+		return nil, problem.InvalidParameter("limit", "must be between 1 and 100")
+	}
+	return data.GetComments(p.Context, taskOf(p).ID, page, limit)
+}
+
+func resolveAttachments(p graphql.ResolveParams) (interface{}, error) {
+	attachments, err := data.GetAttachments(p.Context, taskOf(p).ID)
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+	return attachments, err
+}
+
+func resolveTimeEntries(p graphql.ResolveParams) (interface{}, error) {
+	entries, err := data.GetTimeEntries(p.Context, taskOf(p).ID)
+	if entries == nil {
+		entries = []models.TimeEntry{}
+	}
+	return entries, err
+}
+
+func resolveTimeSpent(p graphql.ResolveParams) (interface{}, error) {
+	totals, err := data.GetTimeTotals(p.Context, data.TimeFilter{TaskID: taskOf(p).ID}, data.GroupByTask)
+	if err != nil || len(totals) == 0 {
+		return 0, err
+	}
+	return totals[0].Duration, nil
+}
+
+func resolveDueDate(p graphql.ResolveParams) (interface{}, error) {
+	if due := taskOf(p).DueDate; !due.IsZero() {
+		return due, nil
+	}
+	return nil, nil
+}
+
+func resolveTags(p graphql.ResolveParams) (interface{}, error) {
+	return nonNil(taskOf(p).Tags), nil
+}
+
+func resolveAssignees(p graphql.ResolveParams) (interface{}, error) {
+	return nonNil(taskOf(p).Assignees), nil
+}
+
+func resolveHighlights(p graphql.ResolveParams) (interface{}, error) {
+	result := p.Source.(models.SearchResult)
+	highlights := make([]map[string]interface{}, 0, len(result.Highlights))
+	for field, snippet := range result.Highlights {
+		highlights = append(highlights, map[string]interface{}{"field": field, "snippet": snippet})
+	}
+	return highlights, nil
+}
+
+// taskOf returns the task a field is resolved on, which the data layer returns either by value
+// or by pointer.
+func taskOf(p graphql.ResolveParams) *models.Task {
+	if task, ok := p.Source.(models.Task); ok {
+		return &task
+	}
+	return p.Source.(*models.Task)
+}
+
+// taskInput converts a TaskInput argument to the payload the data layer expects.
+func taskInput(input map[string]interface{}) models.TaskIdLess {
+	task := models.TaskIdLess{
+		Title:       input["title"].(string),
+		Description: input["description"].(string),
+		DueDate:     optionalTime(input, "due_date"),
+		Tags:        stringsArg(input["tags"]),
+		Assignees:   stringsArg(input["assignees"]),
+	}
+	task.Status, _ = input["status"].(string)
+	task.Priority, _ = input["priority"].(string)
+	task.Estimate, _ = input["estimate"].(float64)
+	return task
+}
+
+// stringsArg converts a list of strings argument, which GraphQL decodes as a []interface{}.
+func stringsArg(value interface{}) []string {
+	list, _ := value.([]interface{})
+	if list == nil {
+		return nil
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		values = append(values, item.(string))
+	}
+	return values
+}
+
+// nonNil returns values, or an empty slice if it is nil, since the list fields are not nullable.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
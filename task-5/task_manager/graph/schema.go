@@ -0,0 +1,394 @@
+// Package graph serves the tasks over GraphQL, so a client can fetch tasks, their comments,
+// attachments and time entries, and aggregates such as the board or the statistics, in a single
+// round trip. Fields are named as in the JSON of the REST API, and every field is resolved through
+// the same data layer and validation rules as the REST controllers.
+package graph
+
+import (
+	"task_manager/data"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// schema is the GraphQL schema served by Handler.
+var schema graphql.Schema
+
+func init() {
+	var err error
+	schema, err = graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+	if err != nil {
+		panic(err)
+	}
+}
+
+var objectIDType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "ObjectID",
+	Description: "A MongoDB ObjectID in hexadecimal.",
+	Serialize: func(value interface{}) interface{} {
+		switch id := value.(type) {
+		case primitive.ObjectID:
+			return id.Hex()
+		case *primitive.ObjectID:
+			if id != nil {
+				return id.Hex()
+			}
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if hex, ok := value.(string); ok {
+			return parseObjectID(hex)
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		if hex, ok := value.(*ast.StringValue); ok {
+			return parseObjectID(hex.Value)
+		}
+		return nil
+	},
+})
+
+// parseObjectID parses hex as an ObjectID, returning nil so GraphQL reports the value as invalid
+// if it is malformed.
+func parseObjectID(hex string) interface{} {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+	return id
+}
+
+var nonNullString = graphql.NewNonNull(graphql.String)
+var nonNullInt = graphql.NewNonNull(graphql.Int)
+var nonNullID = graphql.NewNonNull(objectIDType)
+var nonNullDateTime = graphql.NewNonNull(graphql.DateTime)
+var stringList = graphql.NewNonNull(graphql.NewList(nonNullString))
+
+var commentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Comment",
+	Fields: graphql.Fields{
+		"id":         {Type: nonNullID},
+		"task_id":    {Type: nonNullID},
+		"author":     {Type: nonNullString},
+		"body":       {Type: nonNullString},
+		"created_at": {Type: nonNullDateTime},
+		"updated_at": {Type: nonNullDateTime},
+	},
+})
+
+var commentPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CommentPage",
+	Fields: graphql.Fields{
+		"comments": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType)))},
+		"page":     {Type: nonNullInt},
+		"limit":    {Type: nonNullInt},
+		"total":    {Type: nonNullInt},
+	},
+})
+
+var attachmentType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Attachment",
+	Description: "The metadata of a file attached to a task. The content is downloaded from the REST API.",
+	Fields: graphql.Fields{
+		"id":           {Type: nonNullID},
+		"task_id":      {Type: nonNullID},
+		"filename":     {Type: nonNullString},
+		"content_type": {Type: nonNullString},
+		"size":         {Type: nonNullInt},
+		"sha256":       {Type: nonNullString},
+		"created_at":   {Type: nonNullDateTime},
+	},
+})
+
+var timeEntryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TimeEntry",
+	Fields: graphql.Fields{
+		"id":               {Type: nonNullID},
+		"task_id":          {Type: nonNullID},
+		"user":             {Type: nonNullString},
+		"start":            {Type: nonNullDateTime},
+		"end":              {Type: graphql.DateTime, Description: "Null while the timer is running."},
+		"duration_seconds": {Type: nonNullInt},
+		"running":          {Type: graphql.NewNonNull(graphql.Boolean)},
+		"note":             {Type: graphql.String},
+	},
+})
+
+var taskType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Task",
+	Fields: graphql.Fields{
+		"id":           {Type: nonNullID},
+		"title":        {Type: nonNullString},
+		"description":  {Type: nonNullString},
+		"due_date":     {Type: graphql.DateTime, Description: "Null when the task has no due date.", Resolve: resolveDueDate},
+		"status":       {Type: nonNullString},
+		"tags":         {Type: stringList, Resolve: resolveTags},
+		"assignees":    {Type: stringList, Resolve: resolveAssignees},
+		"priority":     {Type: nonNullString},
+		"estimate":     {Type: graphql.Float, Description: "Expected effort in hours."},
+		"rank":         {Type: nonNullString, Description: "Position of the task within its status column of the board."},
+		"created_at":   {Type: nonNullDateTime},
+		"completed_at": {Type: graphql.DateTime, Description: "Set while the status is a done status."},
+		"comments": {
+			Type:        graphql.NewNonNull(commentPageType),
+			Description: "A page of the comments of the task, oldest first.",
+			Args: graphql.FieldConfigArgument{
+				"page":  {Type: graphql.Int, DefaultValue: 1},
+				"limit": {Type: graphql.Int, DefaultValue: defaultCommentLimit},
+			},
+			Resolve: resolve(resolveComments),
+		},
+		"attachments": {
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(attachmentType))),
+			Resolve: resolve(resolveAttachments),
+		},
+		"time_entries": {
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(timeEntryType))),
+			Description: "The time entries of the task, most recent first.",
+			Resolve:     resolve(resolveTimeEntries),
+		},
+		"time_spent_seconds": {
+			Type:        nonNullInt,
+			Description: "The time spent on the task, summed over its stopped time entries.",
+			Resolve:     resolve(resolveTimeSpent),
+		},
+	},
+})
+
+var taskPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TaskPage",
+	Fields: graphql.Fields{
+		"tasks":  {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType)))},
+		"offset": {Type: nonNullInt},
+		"limit":  {Type: nonNullInt},
+		"total":  {Type: nonNullInt, Description: "The number of tasks matching the filter, over every page."},
+	},
+})
+
+var highlightType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Highlight",
+	Description: "A snippet of a matching field, where the matched terms are wrapped in <mark> tags.",
+	Fields: graphql.Fields{
+		"field":   {Type: nonNullString},
+		"snippet": {Type: nonNullString},
+	},
+})
+
+var searchResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchResult",
+	Fields: graphql.Fields{
+		"task":       {Type: graphql.NewNonNull(taskType)},
+		"score":      {Type: graphql.NewNonNull(graphql.Float)},
+		"highlights": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(highlightType))), Resolve: resolveHighlights},
+	},
+})
+
+var boardColumnType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BoardColumn",
+	Fields: graphql.Fields{
+		"status": {Type: nonNullString},
+		"tasks":  {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType)))},
+	},
+})
+
+var boardType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Board",
+	Fields: graphql.Fields{
+		"columns": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(boardColumnType)))},
+	},
+})
+
+var tagType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Tag",
+	Fields: graphql.Fields{
+		"name":  {Type: nonNullString},
+		"color": {Type: nonNullString},
+		"count": {Type: nonNullInt, Description: "The number of tasks using the tag."},
+	},
+})
+
+var weeklyStatsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "WeeklyStats",
+	Fields: graphql.Fields{
+		"week":      {Type: nonNullString, Description: "An ISO week such as \"2024-W35\"."},
+		"created":   {Type: nonNullInt},
+		"completed": {Type: nonNullInt},
+	},
+})
+
+var burndownDayType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BurndownDay",
+	Fields: graphql.Fields{
+		"date":      {Type: nonNullString},
+		"remaining": {Type: nonNullInt},
+	},
+})
+
+var statsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Stats",
+	Fields: graphql.Fields{
+		"from":                {Type: nonNullString},
+		"to":                  {Type: nonNullString},
+		"tag":                 {Type: graphql.String},
+		"created":             {Type: nonNullInt},
+		"completed":           {Type: nonNullInt},
+		"completion_rate":     {Type: graphql.NewNonNull(graphql.Float)},
+		"average_cycle_hours": {Type: graphql.NewNonNull(graphql.Float)},
+		"overdue":             {Type: nonNullInt},
+		"weekly":              {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(weeklyStatsType)))},
+		"burndown":            {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(burndownDayType)))},
+	},
+})
+
+var timeTotalType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TimeTotal",
+	Fields: graphql.Fields{
+		"key":              {Type: nonNullString, Description: "The task ID, the user or the YYYY-MM-DD day, depending on the grouping."},
+		"duration_seconds": {Type: nonNullInt},
+		"entries":          {Type: nonNullInt},
+	},
+})
+
+var timeGroupingType = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TimeGrouping",
+	Values: graphql.EnumValueConfigMap{
+		"TASK": {Value: data.GroupByTask},
+		"USER": {Value: data.GroupByUser},
+		"DAY":  {Value: data.GroupByDay},
+	},
+})
+
+var taskFilterType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "TaskFilter",
+	Description: "The filters and sort of a task list, with the same meaning as the query parameters of GET /v1/tasks.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"tags":     {Type: graphql.NewList(nonNullString)},
+		"match":    {Type: graphql.String, Description: "\"any\" (the default) or \"all\" of the tags."},
+		"assignee": {Type: graphql.String, Description: "\"me\" stands for the user given in the X-User-ID header."},
+		"query":    {Type: graphql.String, Description: "A filter in the task query language."},
+		"sort":     {Type: graphql.String, Description: "\"smart\" puts open tasks first, ranked by priority and due date."},
+	},
+})
+
+var taskInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "TaskInput",
+	Description: "A task to create or replace, validated as by the REST API.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":       {Type: nonNullString},
+		"description": {Type: nonNullString},
+		"due_date":    {Type: graphql.DateTime},
+		"status":      {Type: graphql.String},
+		"tags":        {Type: graphql.NewList(nonNullString)},
+		"assignees":   {Type: graphql.NewList(nonNullString)},
+		"priority":    {Type: graphql.String},
+		"estimate":    {Type: graphql.Float},
+	},
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"task": {
+			Type:        taskType,
+			Description: "The task with the given ID, or null if there is none.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: nonNullID}},
+			Resolve:     resolve(resolveTask),
+		},
+		"tasks": {
+			Type:        graphql.NewNonNull(taskPageType),
+			Description: "A page of the tasks matching the filter.",
+			Args: graphql.FieldConfigArgument{
+				"filter": {Type: taskFilterType},
+				"offset": {Type: graphql.Int, DefaultValue: 0},
+				"limit":  {Type: graphql.Int, DefaultValue: defaultTaskLimit},
+			},
+			Resolve: resolve(resolveTasks),
+		},
+		"search": {
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(searchResultType))),
+			Description: "A full-text search over the task titles and descriptions, most relevant first.",
+			Args: graphql.FieldConfigArgument{
+				"q":     {Type: nonNullString},
+				"limit": {Type: graphql.Int, DefaultValue: defaultSearchLimit},
+			},
+			Resolve: resolve(resolveSearch),
+		},
+		"board": {
+			Type:        graphql.NewNonNull(boardType),
+			Description: "The tasks grouped into status columns, optionally restricted to the given columns in the given order.",
+			Args:        graphql.FieldConfigArgument{"columns": {Type: graphql.NewList(nonNullString)}},
+			Resolve:     resolve(resolveBoard),
+		},
+		"tags": {
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+			Description: "The tag catalogue.",
+			Resolve:     resolve(resolveTagCatalogue),
+		},
+		"stats": {
+			Type:        graphql.NewNonNull(statsType),
+			Description: "The task statistics over a period, the last 28 days by default, optionally restricted to the tasks carrying tag.",
+			Args: graphql.FieldConfigArgument{
+				"from": {Type: graphql.DateTime},
+				"to":   {Type: graphql.DateTime},
+				"tag":  {Type: graphql.String},
+			},
+			Resolve: resolve(resolveStats),
+		},
+		"time_totals": {
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(timeTotalType))),
+			Description: "The time spent, summed per task, per user or per day.",
+			Args: graphql.FieldConfigArgument{
+				"group_by": {Type: timeGroupingType, DefaultValue: data.GroupByDay},
+				"task_id":  {Type: objectIDType},
+				"user":     {Type: graphql.String},
+				"from":     {Type: graphql.DateTime},
+				"to":       {Type: graphql.DateTime},
+			},
+			Resolve: resolve(resolveTimeTotals),
+		},
+	},
+})
+
+var mutationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Mutation",
+	Fields: graphql.Fields{
+		"create_task": {
+			Type:    graphql.NewNonNull(taskType),
+			Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(taskInputType)}},
+			Resolve: resolve(resolveCreateTask),
+		},
+		"update_task": {
+			Type:        graphql.NewNonNull(taskType),
+			Description: "Replaces the task with the given ID. Unlike on creation, the due date may be in the past.",
+			Args: graphql.FieldConfigArgument{
+				"id":    {Type: nonNullID},
+				"input": {Type: graphql.NewNonNull(taskInputType)},
+			},
+			Resolve: resolve(resolveUpdateTask),
+		},
+		"delete_task": {
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "Deletes the task with the given ID together with its comments, attachments and time entries.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: nonNullID}},
+			Resolve:     resolve(resolveDeleteTask),
+		},
+		"restore_task": {
+			Type:        graphql.NewNonNull(taskType),
+			Description: "Brings back a deleted task together with its comments, attachments and time entries.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: nonNullID}},
+			Resolve:     resolve(resolveRestoreTask),
+		},
+	},
+})
+
+// optionalTime returns the time argument name, or the zero time if it was not given.
+func optionalTime(args map[string]interface{}, name string) time.Time {
+	if value, ok := args[name].(time.Time); ok {
+		return value
+	}
+	return time.Time{}
+}
@@ -1,10 +1,129 @@
 package main
-import(
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"task_manager/controllers"
+	"task_manager/data"
+	"task_manager/logging"
+	"task_manager/metrics"
+	"task_manager/middleware"
 	"task_manager/router"
-	
-) 
+	"task_manager/rpc"
+	"task_manager/tracing"
+	"time"
+)
+
+const (
+	// connectTimeout bounds the connection to the database at startup.
+	connectTimeout = 10 * time.Second
+	// shutdownTimeout bounds the time given to the requests in progress once a stop signal is received.
+	shutdownTimeout = 15 * time.Second
+)
+
+// main serves the API until SIGINT or SIGTERM, then stops accepting connections, waits for the
+// requests in progress and disconnects from the database.
+// The gRPC TaskService is served on GRPC_ADDR, "localhost:9090" by default, next to the HTTP API.
+// The DRAIN_DELAY environment variable, a Go duration such as "5s", keeps the server running that
+// long after the signal with /readyz failing, so the orchestrator stops routing traffic to it first.
+// Logs are written to the standard output as JSON lines, from the level named by LOG_LEVEL
+// ("debug", "info", "warn" or "error", "info" by default). Rate limits are read from the JSON file
+// named by RATE_LIMIT_CONFIG, if any, and database deadlines from MONGO_TIMEOUT and
+// MONGO_AGGREGATION_TIMEOUT.
+func main() {
+	slog.SetDefault(logging.New(os.Stdout, logging.ParseLevel(os.Getenv("LOG_LEVEL"))))
+
+	drainDelay, err := time.ParseDuration(envOr("DRAIN_DELAY", "0s"))
+	if err != nil {
+		fatal("invalid DRAIN_DELAY", err)
+	}
+	rateLimits, err := middleware.LoadRateLimits(os.Getenv("RATE_LIMIT_CONFIG"))
+	if err != nil {
+		fatal("invalid rate limit configuration", err)
+	}
+	if err := data.ConfigureTimeouts(); err != nil {
+		fatal("invalid database timeouts", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	err = data.Connect(connectCtx)
+	cancel()
+	if err != nil {
+		fatal("failed to connect to the database", err)
+	}
+	metrics.CountTasksWith(data.CountOpenTasks)
+
+	server := &http.Server{Addr: "localhost:8080", Handler: router.SetUpRouter(rateLimits)}
+	grpcListener, err := net.Listen("tcp", envOr("GRPC_ADDR", "localhost:9090"))
+	if err != nil {
+		fatal("failed to listen for gRPC", err)
+	}
+	grpcServer := rpc.NewServer()
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	go func() {
+		serverErr <- grpcServer.Serve(grpcListener)
+	}()
+
+	failed := false
+	select {
+	case err := <-serverErr:
+		slog.Error("server stopped", "error", err.Error())
+		failed = true
+	case <-ctx.Done():
+		stop()
+		slog.Info("shutting down")
+		controllers.Drain()
+		grpcServer.Drain()
+		time.Sleep(drainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to shut down the server", "error", err.Error())
+		failed = true
+	}
+	grpcServer.Stop(shutdownCtx)
+	if err := data.Disconnect(shutdownCtx); err != nil {
+		slog.Error("failed to disconnect from the database", "error", err.Error())
+		failed = true
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush the traces", "error", err.Error())
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err.Error())
+	os.Exit(1)
+}
 
-func main(){
-	r := router.SetUpRouter()
-	r.Run("localhost:8080")
-}
\ No newline at end of file
+// envOr returns the value of the environment variable name, or fallback when it is not set.
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,36 @@
+// Package metrics records Prometheus metrics about the HTTP API, the MongoDB operations and the
+// tasks themselves, and exposes them in the Prometheus text format.
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "task_manager"
+
+// Registry holds every metric of the service, along with the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight,
+		mongoDuration,
+		taskCounts,
+	)
+}
+
+// Handler serves the metrics of Registry. A metric which can't be collected, such as the task
+// counts while the database is down, is logged and left out rather than failing the whole scrape.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
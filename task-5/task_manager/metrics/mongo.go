@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var mongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "mongo_operation_duration_seconds",
+	Help:      "Time taken by MongoDB commands, by command, collection and outcome.",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 15},
+}, []string{"command", "collection", "outcome"})
+
+// MongoMonitor returns a command monitor timing every command sent to MongoDB.
+// It is meant to be set on the client options with SetMonitor.
+func MongoMonitor() *event.CommandMonitor {
+	// collections remembers the collection of each command in progress, by request ID,
+	// since only the started event carries the command itself.
+	var collections sync.Map
+	finished := func(requestID int64, command string, duration float64, outcome string) {
+		collection, _ := collections.LoadAndDelete(requestID)
+		name, _ := collection.(string)
+		mongoDuration.WithLabelValues(command, name, outcome).Observe(duration)
+	}
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			field := evt.CommandName
+			if field == "getMore" {
+				field = "collection"
+			}
+			collection, _ := evt.Command.Lookup(field).StringValueOK()
+			collections.Store(evt.RequestID, collection)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.RequestID, evt.CommandName, evt.Duration.Seconds(), "ok")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.RequestID, evt.CommandName, evt.Duration.Seconds(), "error")
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TaskCounter counts the open tasks and, among them, the overdue ones.
+type TaskCounter func(ctx context.Context) (open int, overdue int, err error)
+
+// taskCountTimeout bounds the counting done on each scrape.
+const taskCountTimeout = 5 * time.Second
+
+var (
+	openTasksDesc = prometheus.NewDesc(namespace+"_tasks_open", "Tasks whose status is not a done status.", nil, nil)
+	overdueDesc   = prometheus.NewDesc(namespace+"_tasks_overdue", "Open tasks whose due date has passed.", nil, nil)
+
+	taskCounts = &taskCollector{}
+)
+
+// CountTasksWith sets the function counting the tasks on each scrape.
+// Until it is called, the task gauges are not reported.
+func CountTasksWith(count TaskCounter) {
+	taskCounts.mu.Lock()
+	defer taskCounts.mu.Unlock()
+	taskCounts.count = count
+}
+
+// taskCollector reports the task gauges, counted from the database when metrics are scraped
+// so they are never stale.
+type taskCollector struct {
+	mu    sync.Mutex
+	count TaskCounter
+}
+
+func (t *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openTasksDesc
+	ch <- overdueDesc
+}
+
+func (t *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	count := t.count
+	t.mu.Unlock()
+	if count == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taskCountTimeout)
+	defer cancel()
+	open, overdue, err := count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openTasksDesc, err)
+		ch <- prometheus.NewInvalidMetric(overdueDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(openTasksDesc, prometheus.GaugeValue, float64(open))
+	ch <- prometheus.MustNewConstMetric(overdueDesc, prometheus.GaugeValue, float64(overdue))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// kindStatus maps the kinds of data layer errors to HTTP statuses.
+var kindStatus = map[data.Kind]int{
+	data.KindNotFound:    http.StatusNotFound,
+	data.KindConflict:    http.StatusConflict,
+	data.KindForbidden:   http.StatusForbidden,
+	data.KindInvalid:     http.StatusBadRequest,
+	data.KindTooLarge:    http.StatusRequestEntityTooLarge,
+	data.KindUnsupported: http.StatusUnsupportedMediaType,
+	data.KindTimeout:     http.StatusGatewayTimeout,
+}
+
+// Problems turns the error a handler reported with c.Error into an application/problem+json
+// response. Errors of the problem package and domain errors of the data layer are described
+// to the client; any other error is logged and reported as a generic internal error, so
+// driver messages never leak. Handlers which already wrote a response are left alone.
+func Problems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Abort(c, c.Errors.Last().Err)
+	}
+}
+
+// Abort writes the problem describing err and stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	p := toProblem(err).Problem()
+	p.Instance = c.Request.URL.Path
+	p.RequestID = GetRequestID(c)
+	if p.Status == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "request failed",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "error", err.Error())
+	}
+
+	c.Header("Content-Type", problem.ContentType)
+	c.Render(p.Status, render.JSON{Data: p})
+	c.Abort()
+}
+
+func toProblem(err error) *problem.Error {
+	var apiErr *problem.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if data.IsTimeout(err) {
+		err = data.ErrTimeout
+	}
+	var domainErr *data.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := kindStatus[domainErr.Kind]; ok {
+			return problem.New(status, domainErr.Code, domainErr.Message)
+		}
+	}
+	return problem.New(http.StatusInternalServerError, problem.CodeInternal, "an unexpected error occurred")
+}
+
+// NoRoute reports requests to unknown routes as problems.
+func NoRoute(c *gin.Context) {
+	Abort(c, problem.New(http.StatusNotFound, problem.CodeNotFound, "no route matches "+c.Request.URL.Path))
+}
+
+// NoMethod reports requests with a method the route does not support as problems.
+func NoMethod(c *gin.Context) {
+	Abort(c, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, c.Request.Method+" is not allowed on "+c.Request.URL.Path))
+}
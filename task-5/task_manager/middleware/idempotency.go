@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the header making a POST or PATCH request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed from an earlier request with the same idempotency key.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotentBody is the largest body of a request with an idempotency key, which is held in
+// memory to be hashed.
+const maxIdempotentBody = 1 << 20
+
+var validIdempotencyKey = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// Idempotency honors the Idempotency-Key header of POST and PATCH requests. The first request with
+// a key is handled as usual and its response stored; a retry with the same key and the same body
+// gets the stored response back with an Idempotent-Replayed header instead of running again.
+// Reusing a key with another request is rejected with 422 Unprocessable Entity, and retrying
+// while the first request is still being handled with 409 Conflict, until data.IdempotencyLease
+// has passed and the retry takes the key over. Keys are scoped to the client, see clientKey, and
+// forgotten after data.IdempotencyKeyTTL. Responses with a server error are not stored, so the
+// request can be retried. Multipart uploads are not covered. Requests are compared by route
+// rather than by path, so a retry through the deprecated alias of a route under versionPrefix
+// matches the first request.
+func Idempotency(versionPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		method := c.Request.Method
+		if key == "" || (method != http.MethodPost && method != http.MethodPatch) ||
+			strings.HasPrefix(c.ContentType(), "multipart/") {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey.MatchString(key) {
+			Abort(c, problem.InvalidParameter(IdempotencyKeyHeader, "must be 1 to 255 printable ASCII characters"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBody+1))
+		if err != nil {
+			Abort(c, problem.InvalidBody("the request body could not be read"))
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			Abort(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeInvalidBody,
+				"the body of a request with an Idempotency-Key can't exceed 1 MB"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := hashRequest(c, versionPrefix, body)
+
+		id := clientKey(c) + "|" + key
+		existing, err := data.ReserveIdempotencyKey(c.Request.Context(), id, requestHash)
+		switch {
+		case err != nil:
+			Abort(c, err)
+			return
+		case existing == nil:
+		case existing.RequestHash != requestHash:
+			Abort(c, problem.New(http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused,
+				"the Idempotency-Key was already used with another request"))
+			return
+		case !existing.Completed:
+			Abort(c, problem.New(http.StatusConflict, problem.CodeIdempotencyKeyInProgress,
+				"a request with this Idempotency-Key is still being handled"))
+			return
+		default:
+			c.Header(ReplayedHeader, "true")
+			c.Data(existing.Status, existing.ContentType, existing.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// The outcome is stored even if the client went away, since it may well retry.
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		defer func() {
+			if !completed {
+				if err := data.ReleaseIdempotencyKey(ctx, id); err != nil {
+					slog.ErrorContext(ctx, "failed to release idempotency key", "error", err.Error())
+				}
+			}
+		}()
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		err = data.CompleteIdempotencyKey(ctx, id, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to store idempotent response", "error", err.Error())
+			return
+		}
+		completed = true
+	}
+}
+
+// hashRequest hashes what identifies a request: its method, its route template without
+// versionPrefix, the values of the path parameters, its query string and its body.
+func hashRequest(c *gin.Context, versionPrefix string, body []byte) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	if versionPrefix != "" && strings.HasPrefix(route, versionPrefix+"/") {
+		route = strings.TrimPrefix(route, versionPrefix)
+	}
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+" "+route+"\n")
+	for _, param := range c.Params {
+		io.WriteString(hash, param.Key+"="+param.Value+"\n")
+	}
+	io.WriteString(hash, c.Request.URL.RawQuery+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the body written to the response.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestHashes returns the hash of a POST request with body to each of the targets, served by the
+// route /v1/tasks/:id/comments and its unversioned alias.
+func requestHashes(t *testing.T, body string, targets ...string) []string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var hashes []string
+	handler := func(c *gin.Context) {
+		hashes = append(hashes, hashRequest(c, "/v1", []byte(body)))
+		c.Status(http.StatusNoContent)
+	}
+	engine := gin.New()
+	engine.POST("/v1/tasks/:id/comments", handler)
+	engine.POST("/tasks/:id/comments", handler)
+	for _, target := range targets {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, target, nil))
+	}
+	if len(hashes) != len(targets) {
+		t.Fatalf("got %d hashes, want %d", len(hashes), len(targets))
+	}
+	return hashes
+}
+
+func TestHashRequestSharedByAlias(t *testing.T) {
+	hashes := requestHashes(t, `{"content":"hi"}`, "/v1/tasks/1/comments?notify=true", "/tasks/1/comments?notify=true")
+	if hashes[0] != hashes[1] {
+		t.Error("a retry through the alias hashes differently from the /v1 request")
+	}
+}
+
+func TestHashRequestDistinguishesRequests(t *testing.T) {
+	targets := []string{"/v1/tasks/1/comments", "/v1/tasks/2/comments", "/v1/tasks/1/comments?notify=true"}
+	hashes := requestHashes(t, `{"content":"hi"}`, targets...)
+	seen := make(map[string]string)
+	for i, hash := range hashes {
+		if previous, ok := seen[hash]; ok {
+			t.Errorf("%s hashes like %s", targets[i], previous)
+		}
+		seen[hash] = targets[i]
+	}
+	if other := requestHashes(t, `{"content":"bye"}`, targets[0]); other[0] == hashes[0] {
+		t.Error("requests with different bodies hash alike")
+	}
+}
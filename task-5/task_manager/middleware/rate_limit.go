@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"task_manager/problem"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the header carrying the API key of a client. Keys only identify clients for
+// rate limiting and idempotency; they are not checked.
+const APIKeyHeader = "X-API-Key"
+
+// userHeader identifies the user making a request, see controllers.UserHeader.
+const userHeader = "X-User-ID"
+
+// bucketIdleTime is how long a full bucket is kept once its client stopped sending requests.
+const bucketIdleTime = 10 * time.Minute
+
+// Duration is a time.Duration read from JSON as a Go duration string such as "1m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Limit lets a client make Requests requests Per period on average, in bursts of up to Burst
+// requests. A limit without requests lets everything through.
+type Limit struct {
+	Requests int      `json:"requests"`
+	Per      Duration `json:"per"`
+	Burst    int      `json:"burst"`
+}
+
+func (l Limit) unlimited() bool {
+	return l.Requests <= 0
+}
+
+// refill returns the number of tokens added to a bucket per second.
+func (l Limit) refill() float64 {
+	return float64(l.Requests) / time.Duration(l.Per).Seconds()
+}
+
+// RateLimits configures the rate limiting. Routes are keyed by method and route template,
+// e.g. "POST /v1/tasks"; the other routes share the Default limit. When VersionPrefix is set, a
+// route without it which is an alias of a route with it, such as "POST /tasks" for "POST /v1/tasks",
+// shares the limit and the buckets of that route. PerAddress caps the requests of each IP address
+// to the limited routes, whatever the clients sending them claim to be. TrustedProxies lists the
+// addresses or CIDR ranges of the proxies whose X-Forwarded-For header gives the client address;
+// by default none is trusted and the address is the one connecting to the server.
+type RateLimits struct {
+	Default        Limit            `json:"default"`
+	Routes         map[string]Limit `json:"routes"`
+	PerAddress     Limit            `json:"per_address"`
+	TrustedProxies []string         `json:"trusted_proxies"`
+	VersionPrefix  string           `json:"-"`
+}
+
+// DefaultRateLimits returns the limits used when no configuration is given: 20 requests per
+// second with bursts of 40 for most routes, 30 task creations per minute with bursts of 10,
+// and no limit on the health probes and metrics. An address may send 200 requests per second
+// with bursts of 400, so a few clients behind one NAT don't get in each other's way.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Default:    Limit{Requests: 20, Per: Duration(time.Second), Burst: 40},
+		PerAddress: Limit{Requests: 200, Per: Duration(time.Second), Burst: 400},
+		Routes: map[string]Limit{
+			"POST /v1/tasks": {Requests: 30, Per: Duration(time.Minute), Burst: 10},
+			"GET /healthz":   {},
+			"GET /readyz":    {},
+			"GET /metrics":   {},
+		},
+	}
+}
+
+// LoadRateLimits reads the limits from the JSON file at path, on top of DefaultRateLimits:
+// the file may replace the default limit and the limit per address, and replace or add route limits.
+// An empty path returns the defaults.
+func LoadRateLimits(path string) (RateLimits, error) {
+	limits := DefaultRateLimits()
+	if path == "" {
+		return limits, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return limits, err
+	}
+	var file struct {
+		Default        *Limit           `json:"default"`
+		Routes         map[string]Limit `json:"routes"`
+		PerAddress     *Limit           `json:"per_address"`
+		TrustedProxies []string         `json:"trusted_proxies"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return limits, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Default != nil {
+		if limits.Default, err = checkLimit(*file.Default); err != nil {
+			return limits, fmt.Errorf("%s: default: %w", path, err)
+		}
+	}
+	if file.PerAddress != nil {
+		if limits.PerAddress, err = checkLimit(*file.PerAddress); err != nil {
+			return limits, fmt.Errorf("%s: per_address: %w", path, err)
+		}
+	}
+	for _, proxy := range file.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return limits, fmt.Errorf("%s: trusted proxy %q is neither an IP address nor a CIDR range", path, proxy)
+			}
+		}
+	}
+	if file.TrustedProxies != nil {
+		limits.TrustedProxies = file.TrustedProxies
+	}
+	for route, limit := range file.Routes {
+		if limits.Routes[route], err = checkLimit(limit); err != nil {
+			return limits, fmt.Errorf("%s: route %q: %w", path, route, err)
+		}
+	}
+	return limits, nil
+}
+
+// checkLimit makes sure a limit has a period, and gives it a burst of its number of requests
+// when it has none.
+func checkLimit(limit Limit) (Limit, error) {
+	if limit.unlimited() {
+		return limit, nil
+	}
+	if limit.Per <= 0 {
+		return limit, fmt.Errorf("per must be a positive duration")
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+	return limit, nil
+}
+
+// route returns the route whose limit and buckets a request to the route template path shares,
+// and its limit if it has one of its own.
+func (limits RateLimits) route(method string, path string) (string, Limit, bool) {
+	if limits.VersionPrefix != "" && !strings.HasPrefix(path, limits.VersionPrefix+"/") {
+		versioned := method + " " + limits.VersionPrefix + path
+		if limit, ok := limits.Routes[versioned]; ok {
+			return versioned, limit, true
+		}
+	}
+	route := method + " " + path
+	limit, ok := limits.Routes[route]
+	return route, limit, ok
+}
+
+// bucket holds the tokens left to a client on a route as of updated.
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+// buckets holds the buckets of the clients, the most recently used first. Once there are
+// maxBuckets of them, the least recently used one is dropped to make room for a new one, so
+// clients sending from many addresses can't make it grow without bounds.
+type buckets struct {
+	mu    sync.Mutex
+	byKey map[string]*list.Element
+	lru   *list.List
+}
+
+// maxBuckets bounds the number of buckets kept in memory.
+const maxBuckets = 100000
+
+// clock returns the current time, replaced by tests.
+var clock = time.Now
+
+// take refills the bucket of key up to limit and takes a token from it if there is one.
+// It returns the tokens left and whether the request is allowed.
+func (bs *buckets) take(key string, limit Limit, now time.Time) (float64, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	for back := bs.lru.Back(); back != nil && now.Sub(back.Value.(*bucket).updated) > bucketIdleTime; back = bs.lru.Back() {
+		bs.remove(back)
+	}
+	var b *bucket
+	if element, ok := bs.byKey[key]; ok {
+		bs.lru.MoveToFront(element)
+		b = element.Value.(*bucket)
+	} else {
+		if bs.lru.Len() >= maxBuckets {
+			bs.remove(bs.lru.Back())
+		}
+		b = &bucket{key: key, tokens: float64(limit.Burst), updated: now}
+		bs.byKey[key] = bs.lru.PushFront(b)
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.refill())
+	b.updated = now
+	if b.tokens < 1 {
+		return b.tokens, false
+	}
+	b.tokens--
+	return b.tokens, true
+}
+
+func (bs *buckets) remove(element *list.Element) {
+	delete(bs.byKey, element.Value.(*bucket).key)
+	bs.lru.Remove(element)
+}
+
+// RateLimit limits the rate of requests with a token bucket per client and route. Clients are
+// identified by their API key, else their X-User-ID, else their IP address, so users sharing an
+// address behind a NAT or a proxy get a bucket each. As those headers are not checked, each IP
+// address, as seen through the proxies trusted by the engine, also has a bucket shared by all its
+// requests to the limited routes, sized by limits.PerAddress.
+// Every limited response carries X-RateLimit-Limit, the size of the bucket,
+// X-RateLimit-Remaining, the requests left in it, and X-RateLimit-Reset, the seconds until it is
+// full again. A client with an empty bucket gets a 429 Too Many Requests problem with a
+// Retry-After header giving the seconds to wait.
+func RateLimit(limits RateLimits) gin.HandlerFunc {
+	bs := &buckets{byKey: make(map[string]*list.Element), lru: list.New()}
+
+	return func(c *gin.Context) {
+		route, limit, ok := limits.route(c.Request.Method, c.FullPath())
+		if !ok {
+			limit, route = limits.Default, "default"
+		}
+		if limit.unlimited() {
+			c.Next()
+			return
+		}
+
+		now := clock()
+		tokens, allowed := bs.take(route+"|"+clientKey(c), limit, now)
+		if allowed && !limits.PerAddress.unlimited() {
+			// The headers describe the bucket of the client unless the address ran out first.
+			if addressTokens, addressAllowed := bs.take("address|"+c.ClientIP(), limits.PerAddress, now); !addressAllowed {
+				tokens, allowed, limit = addressTokens, false, limits.PerAddress
+			}
+		}
+		refill := limit.refill()
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(int(tokens)))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(limit.Burst)-tokens)/refill))))
+		if !allowed {
+			retryAfter := int(math.Ceil((1 - tokens) / refill))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			Abort(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+				fmt.Sprintf("rate limit of %d requests per %s exceeded", limit.Requests, time.Duration(limit.Per))).
+				With("retry_after", retryAfter))
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey identifies the client of a request: its API key, else its X-User-ID, else its IP address.
+// The headers come first so a mobile client retrying from another network keeps its idempotency keys.
+func clientKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return "key:" + key
+	}
+	if user := strings.TrimSpace(c.GetHeader(userHeader)); user != "" {
+		return "user:" + user
+	}
+	return "ip:" + c.ClientIP()
+}
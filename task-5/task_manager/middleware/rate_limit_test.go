@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitedEngine serves GET /limited under limits, with the clock of the limiter set to *now.
+func limitedEngine(t *testing.T, limits RateLimits, now *time.Time) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	previous := clock
+	clock = func() time.Time { return *now }
+	t.Cleanup(func() { clock = previous })
+
+	engine := gin.New()
+	engine.SetTrustedProxies(limits.TrustedProxies)
+	engine.Use(RateLimit(limits))
+	engine.GET("/limited", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return engine
+}
+
+// get sends GET /limited from remoteAddr with the given headers.
+func get(engine *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitRefillsAndRejects(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{Default: Limit{Requests: 1, Per: Duration(2 * time.Second), Burst: 2}}
+	engine := limitedEngine(t, limits, &now)
+
+	for i, remaining := range []string{"1", "0"} {
+		w := get(engine, "192.0.2.1:1234", nil)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: got status %d, want %d", i+1, w.Code, http.StatusNoContent)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != remaining {
+			t.Errorf("request %d: got X-RateLimit-Remaining %q, want %q", i+1, got, remaining)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: got X-RateLimit-Limit %q, want \"2\"", i+1, got)
+		}
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	w := get(engine, "192.0.2.1:1234", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d once the bucket is empty, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	// A quarter of a token came back in half a second; the rest takes 1.5s, rounded up.
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("got Retry-After %q, want \"2\"", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Reset"); got != "4" {
+		t.Errorf("got X-RateLimit-Reset %q, want \"4\"", got)
+	}
+	var body struct {
+		Code       string `json:"code"`
+		RetryAfter int    `json:"retry_after"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid problem: %v", err)
+	}
+	if body.Code != "rate_limited" || body.RetryAfter != 2 {
+		t.Errorf("got problem code %q with retry_after %d, want rate_limited with 2", body.Code, body.RetryAfter)
+	}
+
+	now = now.Add(2 * time.Second)
+	if w := get(engine, "192.0.2.1:1234", nil); w.Code != http.StatusNoContent {
+		t.Errorf("got status %d once refilled, want %d", w.Code, http.StatusNoContent)
+	}
+	now = now.Add(time.Hour)
+	w = get(engine, "192.0.2.1:1234", nil)
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "1" {
+		t.Errorf("got X-RateLimit-Remaining %q after a long pause, want the burst minus one", got)
+	}
+}
+
+func TestRateLimitIdentifiesClients(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{Default: Limit{Requests: 1, Per: Duration(time.Minute), Burst: 1}}
+	engine := limitedEngine(t, limits, &now)
+
+	clients := []map[string]string{
+		{APIKeyHeader: "key", "X-User-ID": "alice"},
+		{"X-User-ID": "alice"},
+		{"X-User-ID": "bob"},
+		nil,
+	}
+	for _, h := range clients {
+		if w := get(engine, "192.0.2.1:1234", h); w.Code != http.StatusNoContent {
+			t.Errorf("first request with %v: got status %d, want %d", h, w.Code, http.StatusNoContent)
+		}
+	}
+	// The same clients again, from another address: the headers identify them wherever they are.
+	for _, h := range clients[:3] {
+		if w := get(engine, "192.0.2.2:1234", h); w.Code != http.StatusTooManyRequests {
+			t.Errorf("second request with %v: got status %d, want %d", h, w.Code, http.StatusTooManyRequests)
+		}
+	}
+	// Without headers, an untrusted X-Forwarded-For doesn't change the address.
+	if w := get(engine, "192.0.2.1:5678", map[string]string{"X-Forwarded-For": "198.51.100.7"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d from the same address, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := get(engine, "192.0.2.2:1234", nil); w.Code != http.StatusNoContent {
+		t.Errorf("got status %d from another address, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitCapsAddress(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{
+		Default:    Limit{Requests: 10, Per: Duration(time.Minute), Burst: 10},
+		PerAddress: Limit{Requests: 2, Per: Duration(time.Minute), Burst: 2},
+	}
+	engine := limitedEngine(t, limits, &now)
+
+	for _, user := range []string{"alice", "bob"} {
+		w := get(engine, "192.0.2.1:1234", map[string]string{"X-User-ID": user})
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "10" {
+			t.Errorf("%s: got status %d with X-RateLimit-Limit %q, want %d with the limit of the client",
+				user, w.Code, w.Header().Get("X-RateLimit-Limit"), http.StatusNoContent)
+		}
+	}
+	w := get(engine, "192.0.2.1:1234", map[string]string{"X-User-ID": "mallory"})
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d once the address used its quota, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+		t.Errorf("got X-RateLimit-Limit %q, want the limit of the address", got)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("got Retry-After %q, want \"30\"", got)
+	}
+	if w := get(engine, "192.0.2.2:1234", map[string]string{"X-User-ID": "mallory"}); w.Code != http.StatusNoContent {
+		t.Errorf("got status %d from another address, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{
+		Default:        Limit{Requests: 1, Per: Duration(time.Minute), Burst: 1},
+		TrustedProxies: []string{"10.0.0.0/8"},
+	}
+	engine := limitedEngine(t, limits, &now)
+
+	for _, client := range []string{"198.51.100.7", "198.51.100.8"} {
+		w := get(engine, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": client})
+		if w.Code != http.StatusNoContent {
+			t.Errorf("client %s behind the proxy: got status %d, want %d", client, w.Code, http.StatusNoContent)
+		}
+	}
+}
+
+func TestRateLimitUnlimitedRoute(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{
+		Default: Limit{Requests: 1, Per: Duration(time.Minute), Burst: 1},
+		Routes:  map[string]Limit{"GET /limited": {}},
+	}
+	engine := limitedEngine(t, limits, &now)
+
+	for i := 0; i < 3; i++ {
+		w := get(engine, "192.0.2.1:1234", nil)
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("request %d: got status %d with X-RateLimit-Limit %q, want %d without it",
+				i+1, w.Code, w.Header().Get("X-RateLimit-Limit"), http.StatusNoContent)
+		}
+	}
+}
+
+func TestBucketsDropLeastRecentlyUsed(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Per: Duration(time.Minute), Burst: 1}
+	bs := &buckets{byKey: make(map[string]*list.Element), lru: list.New()}
+
+	bs.take("first", limit, now)
+	bs.take("second", limit, now)
+	if _, allowed := bs.take("first", limit, now); allowed {
+		t.Fatal("got a token from an empty bucket")
+	}
+	for i := 0; i < maxBuckets; i++ {
+		bs.take(strconv.Itoa(i), limit, now)
+	}
+	if bs.lru.Len() != maxBuckets {
+		t.Fatalf("got %d buckets, want at most %d", bs.lru.Len(), maxBuckets)
+	}
+	if _, ok := bs.byKey["first"]; ok {
+		t.Error("the least recently used bucket was kept")
+	}
+
+	later := now.Add(bucketIdleTime + time.Second)
+	bs.take("new", limit, later)
+	if bs.lru.Len() != 1 {
+		t.Errorf("got %d buckets once the others were idle, want 1", bs.lru.Len())
+	}
+}
+
+func TestRateLimitAliasesShareVersionedRoute(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	limits := RateLimits{
+		Default:       Limit{Requests: 100, Per: Duration(time.Second), Burst: 100},
+		Routes:        map[string]Limit{"GET /v1/limited": {Requests: 1, Per: Duration(time.Minute), Burst: 1}},
+		VersionPrefix: "/v1",
+	}
+	engine := limitedEngine(t, limits, &now)
+	engine.GET("/v1/limited", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/limited", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w := get(engine, "192.0.2.1:1234", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d on the alias, want %d from the bucket of the /v1 route", w.Code, http.StatusTooManyRequests)
+	}
+}
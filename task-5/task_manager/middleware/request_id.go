@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"task_manager/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the key under which the request ID is stored in the gin context.
+const requestIDKey = "request_id"
+
+// validRequestID restricts the request IDs accepted from clients, so they are safe to log and echo.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID gives every request an ID: the one sent by the client in X-Request-ID when it is
+// well formed, a newly generated one otherwise. The ID is echoed in the response header and
+// carried by the context of the request, where the logs find it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := EnsureRequestID(c.GetHeader(RequestIDHeader))
+		c.Set(requestIDKey, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID of the request, or an empty string outside of the RequestID middleware.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// EnsureRequestID returns id if it is a well formed request ID, such as one sent by a client,
+// and a newly generated ID otherwise.
+func EnsureRequestID(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
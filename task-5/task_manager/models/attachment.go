@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment holds the metadata of a file attached to a task.
+// The content itself lives in the blob storage under the SHA256 key.
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	TaskID      primitive.ObjectID `json:"task_id" bson:"task_id"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	SHA256      string             `json:"sha256" bson:"sha256"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
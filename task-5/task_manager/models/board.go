@@ -0,0 +1,23 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// BoardColumn holds the tasks sharing a status, in board order.
+type BoardColumn struct {
+	Status string `json:"status"`
+	Tasks  []Task `json:"tasks"`
+}
+
+type Board struct {
+	Columns []BoardColumn `json:"columns"`
+}
+
+// TaskMove is the payload used to move a task on the board.
+// The task is placed in the Status column right below the task AfterID and right above
+// the task BeforeID. Giving only one of them is enough; giving none moves the task to
+// the bottom of the column.
+type TaskMove struct {
+	Status   string              `json:"status" binding:"required,status"`
+	AfterID  *primitive.ObjectID `json:"after_id"`
+	BeforeID *primitive.ObjectID `json:"before_id"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Comment struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	Author    string             `json:"author" bson:"author"`
+	Body      string             `json:"body" bson:"body"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CommentBody is the payload used to create or edit a comment.
+type CommentBody struct {
+	Body string `json:"body"`
+}
+
+// CommentPage is a single page of the comments of a task.
+type CommentPage struct {
+	Comments []Comment `json:"comments"`
+	Page     int       `json:"page"`
+	Limit    int       `json:"limit"`
+	Total    int64     `json:"total"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key header and, once it is
+// handled, its response, so a retry of the request gets the same response without running again.
+type IdempotencyRecord struct {
+	ID          string    `bson:"_id"` // the client and the key
+	RequestHash string    `bson:"request_hash"`
+	Completed   bool      `bson:"completed"` // false while the request is being handled
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// LockedUntil ends the lease of the request being handled, after which a retry takes it over,
+	// in case the process handling it died before completing or releasing the key.
+	LockedUntil time.Time `bson:"locked_until,omitempty"`
+}
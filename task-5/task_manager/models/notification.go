@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationMention is the kind of notification sent when a user is @mentioned.
+const NotificationMention = "mention"
+
+type Notification struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	User      string              `json:"user" bson:"user"`
+	Kind      string              `json:"kind" bson:"kind"`
+	TaskID    primitive.ObjectID  `json:"task_id" bson:"task_id"`
+	CommentID *primitive.ObjectID `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Actor     string              `json:"actor,omitempty" bson:"actor,omitempty"`
+	Read      bool                `json:"read" bson:"read"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Stats summarizes the activity on the tasks over a period.
+type Stats struct {
+	From              string        `json:"from"`
+	To                string        `json:"to"`
+	Tag               string        `json:"tag,omitempty"`
+	Created           int           `json:"created"`
+	Completed         int           `json:"completed"`
+	CompletionRate    float64       `json:"completion_rate"`     // share of the tasks created in the period that are done
+	AverageCycleHours float64       `json:"average_cycle_hours"` // mean time from creation to completion of the tasks completed in the period
+	Overdue           int           `json:"overdue"`             // open tasks past their due date, at the time of the request
+	Weekly            []WeeklyStats `json:"weekly"`
+	Burndown          []BurndownDay `json:"burndown"`
+}
+
+// WeeklyStats counts the tasks created and completed during an ISO week such as "2024-W35".
+type WeeklyStats struct {
+	Week      string `json:"week"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// BurndownDay is the number of tasks still open at the end of a day.
+type BurndownDay struct {
+	Date      string `json:"date"`
+	Remaining int    `json:"remaining"`
+}
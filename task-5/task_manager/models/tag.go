@@ -0,0 +1,14 @@
+package models
+
+// Tag is an entry of the tag catalogue. The color is stored in the tags
+// collection while the usage count is computed from the tasks using the tag.
+type Tag struct {
+	Name  string `json:"name" bson:"_id"`
+	Color string `json:"color" bson:"color"`
+	Count int    `json:"count" bson:"-"`
+}
+
+// TagList is the payload used to add tags to a task.
+type TagList struct {
+	Tags []string `json:"tags"`
+}
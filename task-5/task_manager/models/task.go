@@ -1,22 +1,102 @@
 package models
 
 import (
+	"reflect"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Task priorities, from the most to the least urgent.
+const (
+	PriorityP0 = "P0"
+	PriorityP1 = "P1"
+	PriorityP2 = "P2"
+	PriorityP3 = "P3"
+)
+
+// DefaultPriority is given to tasks created without a priority.
+const DefaultPriority = PriorityP2
+
+// ValidPriority reports whether priority is one of P0 to P3.
+func ValidPriority(priority string) bool {
+	switch priority {
+	case PriorityP0, PriorityP1, PriorityP2, PriorityP3:
+		return true
+	}
+	return false
+}
+
+// Statuses lists the statuses a task can have, in board order.
+var Statuses = []string{"todo", "in progress", "done", "completed"}
+
+// ValidStatus reports whether status is one of Statuses, in any case.
+func ValidStatus(status string) bool {
+	for _, valid := range Statuses {
+		if strings.EqualFold(strings.TrimSpace(status), valid) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeStatus returns status in the form it is stored in, trimmed and in lower case, so a
+// status given in any case lands in the same board column.
+func NormalizeStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+// IsDoneStatus reports whether a task with the given status is finished.
+// Both "done" and "completed" are recognized, in any case.
+func IsDoneStatus(status string) bool {
+	switch NormalizeStatus(status) {
+	case "done", "completed":
+		return true
+	}
+	return false
+}
+
 type Task struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id"`
 	Title       string             `json:"title" bson:"title"`
 	Description string             `json:"description" bson:"description"`
 	DueDate     time.Time          `json:"due_date" bson:"due_date"`
 	Status      string             `json:"status" bson:"status"`
+	Tags        []string           `json:"tags" bson:"tags"`
+	Assignees   []string           `json:"assignees" bson:"assignees"`
+	Priority    string             `json:"priority" bson:"priority"`
+	Estimate    float64            `json:"estimate,omitempty" bson:"estimate,omitempty"` // expected effort in hours
+	Rank        string             `json:"rank" bson:"rank"`                             // position within the status column of the board
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty" bson:"completed_at,omitempty"` // set while the status is a done status
+	DeletedAt   *time.Time         `json:"-" bson:"deleted_at,omitempty"`                        // set while the task is deleted
 }
 
+// TaskIdLess is the payload creating or replacing a task.
+// The binding tags are checked when the payload is bound; see the controllers package
+// for the custom notblank, status and priority validators.
 type TaskIdLess struct {
-	Title       string    `json:"title" bson:"title"`
-	Description string    `json:"description" bson:"description"`
+	Title       string    `json:"title" bson:"title" binding:"notblank,max=200"`
+	Description string    `json:"description" bson:"description" binding:"notblank,max=5000"`
 	DueDate     time.Time `json:"due_date" bson:"due_date"`
-	Status      string    `json:"status" bson:"status"`
+	Status      string    `json:"status" bson:"status" binding:"omitempty,status"`
+	Tags        []string  `json:"tags" bson:"tags" binding:"max=20,dive,max=50"`
+	Assignees   []string  `json:"assignees" bson:"assignees" binding:"max=20,dive,max=100"`
+	Priority    string    `json:"priority" bson:"priority" binding:"omitempty,priority"`
+	Estimate    float64   `json:"estimate,omitempty" bson:"estimate,omitempty" binding:"gte=0"`
+}
+
+// TaskFields maps the JSON name of each field of Task to its BSON name.
+var TaskFields = taskFields()
+
+func taskFields() map[string]string {
+	fields := make(map[string]string)
+	t := reflect.TypeOf(Task{})
+	for i := 0; i < t.NumField(); i++ {
+		jsonName := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		bsonName := strings.Split(t.Field(i).Tag.Get("bson"), ",")[0]
+		fields[jsonName] = bsonName
+	}
+	return fields
 }
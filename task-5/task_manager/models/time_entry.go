@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimeEntry is a period a user spent working on a task, either tracked with the
+// timer or entered manually. A running timer has no end and Running set to true.
+type TimeEntry struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	TaskID   primitive.ObjectID `json:"task_id" bson:"task_id"`
+	User     string             `json:"user" bson:"user"`
+	Start    time.Time          `json:"start" bson:"start"`
+	End      *time.Time         `json:"end,omitempty" bson:"end,omitempty"`
+	Duration int64              `json:"duration_seconds" bson:"duration_seconds"`
+	Running  bool               `json:"running" bson:"running"`
+	Note     string             `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// TimeEntryInput is the payload used to create or edit a time entry manually.
+type TimeEntryInput struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Note  string    `json:"note"`
+}
+
+// TimeTotal is the time spent on a task, by a user or during a day, depending on how entries were grouped.
+type TimeTotal struct {
+	Key      string `json:"key" bson:"_id"`
+	Duration int64  `json:"duration_seconds" bson:"duration_seconds"`
+	Entries  int    `json:"entries" bson:"entries"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaskListOptions are the filters, sort and visible fields of a task list,
+// with the same meaning as the query parameters of GET /tasks.
+type TaskListOptions struct {
+	Tags     []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Match    string   `json:"match,omitempty" bson:"match,omitempty"`
+	Assignee string   `json:"assignee,omitempty" bson:"assignee,omitempty"`
+	Query    string   `json:"query,omitempty" bson:"query,omitempty"`
+	Sort     string   `json:"sort,omitempty" bson:"sort,omitempty"`
+	Fields   []string `json:"fields,omitempty" bson:"fields,omitempty"`
+}
+
+// View is a named task list saved by a user, such as "My overdue" or "This week".
+// Views are private to their owner.
+type View struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	Owner           string             `json:"owner" bson:"owner"`
+	Name            string             `json:"name" bson:"name"`
+	TaskListOptions `bson:",inline"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// ViewInput is the payload used to create or replace a view.
+type ViewInput struct {
+	Name string `json:"name"`
+	TaskListOptions
+}
@@ -0,0 +1,29 @@
+// Package openapi holds the OpenAPI 3 description of the API and a page browsing it, both
+// embedded in the binary so the server documents itself without any external asset.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec is the OpenAPI document. The routes it describes are checked against the router by the
+// router tests, so a route added to one must be added to the other.
+//
+//go:embed openapi.json
+var Spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// ServeSpec serves the OpenAPI document.
+func ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", Spec)
+}
+
+// ServeDocs serves the documentation page, which renders the document served by ServeSpec.
+func ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,138 @@
+// Package problem describes API errors as RFC 7807 problem details, served with the
+// application/problem+json media type.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// typePrefix prefixes the stable error code to build the problem type URI.
+const typePrefix = "urn:task-manager:problem:"
+
+// Stable error codes used by the API for request errors. Domain errors carry their own codes.
+const (
+	CodeInvalidBody              = "invalid_body"
+	CodeInvalidParameter         = "invalid_parameter"
+	CodeValidationFailed         = "validation_failed"
+	CodeMissingUser              = "missing_user"
+	CodeInternal                 = "internal_error"
+	CodeNotFound                 = "not_found"
+	CodeMethodNotAllowed         = "method_not_allowed"
+	CodeRateLimited              = "rate_limited"
+	CodeIdempotencyKeyReused     = "idempotency_key_reused"
+	CodeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+)
+
+// FieldError describes what is wrong with a single field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the body of an error response.
+// Extensions holds additional members serialized next to the standard ones.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Errors     []FieldError           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON serializes the problem with its extension members at the top level.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	encoded, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return encoded, err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(encoded, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if _, standard := members[name]; !standard {
+			members[name] = value
+		}
+	}
+	return json.Marshal(members)
+}
+
+// Error is an error a handler reports instead of writing a response itself.
+// The error middleware turns it into a Problem.
+type Error struct {
+	Status     int
+	Code       string
+	Detail     string
+	Fields     []FieldError
+	Extensions map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// New returns an Error with the given status, code and detail.
+func New(status int, code string, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// InvalidBody reports a request body which could not be read.
+func InvalidBody(detail string, fields ...FieldError) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidBody, Detail: detail, Fields: fields}
+}
+
+// InvalidParameter reports a malformed path or query parameter.
+func InvalidParameter(name string, message string) *Error {
+	return &Error{
+		Status: http.StatusBadRequest,
+		Code:   CodeInvalidParameter,
+		Detail: "invalid parameter '" + name + "'",
+		Fields: []FieldError{{Field: name, Message: message}},
+	}
+}
+
+// Validation reports a request whose fields failed validation.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "the request failed validation",
+		Fields: fields,
+	}
+}
+
+// MissingUser reports a request which needs the X-User-ID header but has none.
+func MissingUser() *Error {
+	return New(http.StatusUnauthorized, CodeMissingUser, "X-User-ID header is required")
+}
+
+// With sets an extension member of the problem and returns the error.
+func (e *Error) With(name string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]interface{})
+	}
+	e.Extensions[name] = value
+	return e
+}
+
+// Problem converts the error to a Problem.
+func (e *Error) Problem() Problem {
+	return Problem{
+		Type:       typePrefix + e.Code,
+		Title:      http.StatusText(e.Status),
+		Status:     e.Status,
+		Detail:     e.Detail,
+		Code:       e.Code,
+		Errors:     e.Fields,
+		Extensions: e.Extensions,
+	}
+}
@@ -1,20 +1,111 @@
 package router
 
 import(
+	"io"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"task_manager/controllers"
+	"task_manager/graph"
+	"task_manager/metrics"
+	"task_manager/middleware"
+	"task_manager/openapi"
+	"task_manager/tracing"
+
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// The unversioned routes, which predate /v1, are deprecated aliases of the /v1 ones.
+var (
+	unversionedDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	unversionedSunset     = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
 )
 
-func SetUpRouter()*gin.Engine{
-	router := gin.Default()
+// SetUpRouter builds the engine serving the API, limiting the rate of requests as configured by limits.
+// Each version of the API is a route group registered by its own function, so a version changing
+// the shape of the responses gets its own handlers while the older ones keep theirs.
+// The probes, the metrics and the documentation are not part of the API and are not versioned.
+func SetUpRouter(limits middleware.RateLimits)*gin.Engine{
+	// The deprecated aliases share the limits and the buckets of the /v1 routes, so keeping them
+	// does not give clients twice the quota.
+	limits.VersionPrefix = "/v1"
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.SetTrustedProxies(limits.TrustedProxies) // checked by middleware.LoadRateLimits
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName),
+		metrics.Middleware(),
+		middleware.RequestID(),
+		middleware.Logger(),
+		gin.CustomRecoveryWithWriter(io.Discard, middleware.Recover),
+		middleware.RateLimit(limits),
+		middleware.Idempotency(limits.VersionPrefix),
+		middleware.Problems(),
+	)
+	router.NoRoute(middleware.NoRoute)
+	router.NoMethod(middleware.NoMethod)
 
-	router.GET("/tasks", controllers.GetTasks)
-	router.GET("/tasks/:id", controllers.GetTask)
-	router.POST("/tasks", controllers.CreateTask)
-	router.PUT("/tasks/:id", controllers.UpdateTask)
+	router.GET("/healthz", controllers.Healthz)
+	router.GET("/readyz", controllers.Readyz)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/openapi.json", openapi.ServeSpec)
+	router.GET("/docs", openapi.ServeDocs)
+
+	// GraphQL has a single endpoint whose schema evolves without versions.
+	router.POST("/graphql", graph.Handler)
+
+	registerV1(router.Group("/v1"))
+	registerV1(router.Group("", middleware.Deprecated(unversionedDeprecated, unversionedSunset, "/v1")))
 
-	router.DELETE("/tasks/:id", controllers.DeleteTask)
-	
 	return router
+}
+
+// registerV1 registers the routes of version 1 of the API on api.
+func registerV1(api *gin.RouterGroup) {
+	api.GET("/tasks", controllers.GetTasks)
+	api.GET("/tasks/search", controllers.SearchTasks)
+	api.GET("/tasks/:id", controllers.GetTask)
+	api.POST("/tasks", controllers.CreateTask)
+	api.PUT("/tasks/:id", controllers.UpdateTask)
+
+	api.DELETE("/tasks/:id", controllers.DeleteTask)
+	api.POST("/tasks/:id/restore", controllers.RestoreTask)
+
+	api.POST("/tasks/:id/tags", controllers.AddTaskTags)
+	api.DELETE("/tasks/:id/tags/:tag", controllers.RemoveTaskTag)
+
+	api.POST("/tasks/:id/move", controllers.MoveTask)
+	api.GET("/board", controllers.GetBoard)
+
+	api.POST("/tasks/:id/timer/start", controllers.StartTimer)
+	api.POST("/tasks/:id/timer/stop", controllers.StopTimer)
+	api.GET("/tasks/:id/time-entries", controllers.GetTimeEntries)
+	api.POST("/tasks/:id/time-entries", controllers.CreateTimeEntry)
+	api.PUT("/tasks/:id/time-entries/:entryId", controllers.UpdateTimeEntry)
+	api.DELETE("/tasks/:id/time-entries/:entryId", controllers.DeleteTimeEntry)
+	api.GET("/time-totals", controllers.GetTimeTotals)
+	api.GET("/stats", controllers.GetStats)
+
+	api.GET("/tasks/:id/comments", controllers.GetComments)
+	api.POST("/tasks/:id/comments", controllers.CreateComment)
+	api.PUT("/tasks/:id/comments/:commentId", controllers.UpdateComment)
+	api.DELETE("/tasks/:id/comments/:commentId", controllers.DeleteComment)
+
+	api.GET("/tasks/:id/attachments", controllers.GetAttachments)
+	api.POST("/tasks/:id/attachments", controllers.UploadAttachment)
+	api.GET("/tasks/:id/attachments/:attachmentId", controllers.DownloadAttachment)
+	api.DELETE("/tasks/:id/attachments/:attachmentId", controllers.DeleteAttachment)
+
+	api.GET("/tags", controllers.GetTags)
+	api.PUT("/tags/:tag", controllers.UpdateTag)
+
+	api.GET("/views", controllers.GetViews)
+	api.POST("/views", controllers.CreateView)
+	api.GET("/views/:id", controllers.GetView)
+	api.PUT("/views/:id", controllers.UpdateView)
+	api.DELETE("/views/:id", controllers.DeleteView)
+	api.GET("/views/:id/tasks", controllers.GetViewTasks)
 
-}
\ No newline at end of file
+	api.GET("/notifications", controllers.GetNotifications)
+	api.POST("/notifications/:id/read", controllers.MarkNotificationRead)
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"task_manager/middleware"
+	"task_manager/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pathParam matches a Gin path parameter such as ":id".
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+// specMethods lists the operations of an OpenAPI path item.
+var specMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// TestRoutesMatchSpec fails when a route is registered but not described by the OpenAPI document,
+// or described but not registered. The deprecated unversioned aliases of the /v1 routes are left
+// out, as the document only describes the /v1 ones.
+func TestRoutesMatchSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	routes := SetUpRouter(middleware.DefaultRateLimits()).Routes()
+
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		registered[route.Method+" "+route.Path] = true
+	}
+	served := make(map[string]bool)
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/v1/") && registered[route.Method+" /v1"+route.Path] {
+			continue
+		}
+		served[route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+	described := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for _, method := range specMethods {
+			if _, ok := item[strings.ToLower(method)]; ok {
+				described[method+" "+path] = true
+			}
+		}
+	}
+
+	for _, route := range missing(served, described) {
+		t.Errorf("%s is registered but not described by the OpenAPI document", route)
+	}
+	for _, route := range missing(described, served) {
+		t.Errorf("%s is described by the OpenAPI document but not registered", route)
+	}
+}
+
+// missing returns, sorted, the keys of from which are not in to.
+func missing(from map[string]bool, to map[string]bool) []string {
+	var keys []string
+	for key := range from {
+		if !to[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/taskpb"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventKinds maps the kinds of data.TaskEvent to their protobuf values.
+var eventKinds = map[string]taskpb.TaskEvent_Kind{
+	data.TaskCreated: taskpb.TaskEvent_CREATED,
+	data.TaskUpdated: taskpb.TaskEvent_UPDATED,
+	data.TaskDeleted: taskpb.TaskEvent_DELETED,
+}
+
+// timestamp converts t to a timestamp, leaving it unset when t is the zero time.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toTask converts a task to its protobuf message.
+func toTask(task models.Task) *taskpb.Task {
+	message := &taskpb.Task{
+		Id:          task.ID.Hex(),
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     timestamp(task.DueDate),
+		Status:      task.Status,
+		Tags:        task.Tags,
+		Assignees:   task.Assignees,
+		Priority:    task.Priority,
+		Estimate:    task.Estimate,
+		Rank:        task.Rank,
+		CreatedAt:   timestamp(task.CreatedAt),
+	}
+	if task.CompletedAt != nil {
+		message.CompletedAt = timestamppb.New(*task.CompletedAt)
+	}
+	return message
+}
+
+// fromTaskInput converts a task to create or replace from its protobuf message.
+// An unset due date is the zero time, as a missing due_date is in JSON.
+func fromTaskInput(input *taskpb.TaskInput) models.TaskIdLess {
+	task := models.TaskIdLess{
+		Title:       input.GetTitle(),
+		Description: input.GetDescription(),
+		Status:      input.GetStatus(),
+		Tags:        input.GetTags(),
+		Assignees:   input.GetAssignees(),
+		Priority:    input.GetPriority(),
+		Estimate:    input.GetEstimate(),
+	}
+	if input.GetDueDate() != nil {
+		task.DueDate = input.GetDueDate().AsTime()
+	}
+	return task
+}
+
+// fromTaskFilter converts a filter to the options of a task list.
+func fromTaskFilter(filter *taskpb.TaskFilter) models.TaskListOptions {
+	return models.TaskListOptions{
+		Tags:     filter.GetTags(),
+		Match:    filter.GetMatch(),
+		Assignee: filter.GetAssignee(),
+		Query:    filter.GetQuery(),
+		Sort:     filter.GetSort(),
+	}
+}
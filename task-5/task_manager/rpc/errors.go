@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"task_manager/data"
+	"task_manager/problem"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain of the ErrorInfo details of the errors.
+const errorDomain = "task-manager"
+
+// httpCodes maps the HTTP statuses of request errors to gRPC codes.
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:   codes.InvalidArgument,
+	http.StatusUnauthorized: codes.Unauthenticated,
+	http.StatusForbidden:    codes.PermissionDenied,
+	http.StatusNotFound:     codes.NotFound,
+}
+
+// kindCodes maps the kinds of data layer errors to gRPC codes.
+var kindCodes = map[data.Kind]codes.Code{
+	data.KindNotFound:    codes.NotFound,
+	data.KindConflict:    codes.FailedPrecondition,
+	data.KindForbidden:   codes.PermissionDenied,
+	data.KindInvalid:     codes.InvalidArgument,
+	data.KindTooLarge:    codes.ResourceExhausted,
+	data.KindUnsupported: codes.InvalidArgument,
+	data.KindTimeout:     codes.DeadlineExceeded,
+}
+
+// statusError converts an error of the data layer or of the validation to a gRPC status error
+// carrying its stable code and, for invalid requests, the invalid fields, as the problems of the
+// REST API do. Any other error is internal: it is logged and its text does not reach the client.
+func statusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var requestErr *problem.Error
+	if errors.As(err, &requestErr) {
+		code, ok := httpCodes[requestErr.Status]
+		if !ok {
+			code = codes.Unknown
+		}
+		return withDetails(code, requestErr.Code, requestErr.Detail, requestErr.Fields)
+	}
+	if data.IsTimeout(err) {
+		err = data.ErrTimeout
+	}
+	var domainErr *data.Error
+	if errors.As(err, &domainErr) {
+		if code, ok := kindCodes[domainErr.Kind]; ok {
+			return withDetails(code, domainErr.Code, domainErr.Message, nil)
+		}
+	}
+	slog.ErrorContext(ctx, "internal error", "error", err.Error())
+	return withDetails(codes.Internal, problem.CodeInternal, "an unexpected error occurred", nil)
+}
+
+// withDetails builds a status error with an ErrorInfo detail giving reason and, if there are
+// invalid fields, a BadRequest detail listing them.
+func withDetails(code codes.Code, reason string, message string, fields []problem.FieldError) error {
+	details := []*errdetails.ErrorInfo{{Reason: reason, Domain: errorDomain}}
+	st, err := status.New(code, message).WithDetails(details[0])
+	if err != nil {
+		return status.Error(code, message)
+	}
+	if len(fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+		for _, field := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		if withFields, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			st = withFields
+		}
+	}
+	return st.Err()
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"task_manager/controllers"
+	"task_manager/logging"
+	"task_manager/middleware"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys read from the calls, the lower case names of the HTTP headers with the same role.
+var (
+	userKey      = strings.ToLower(controllers.UserHeader)
+	requestIDKey = strings.ToLower(middleware.RequestIDHeader)
+)
+
+// callUserKey is the context key of the user making a call.
+type callUserKey struct{}
+
+// userFrom returns the user making the call, or "" if the call did not name one.
+func userFrom(ctx context.Context) string {
+	user, _ := ctx.Value(callUserKey{}).(string)
+	return user
+}
+
+// prepare gives a call the context an HTTP request gets from the middleware: its request ID,
+// taken from the metadata when well formed and sent back in the header, and its user.
+func prepare(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	id := middleware.EnsureRequestID(first(requestIDKey))
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logging.WithRequestID(ctx, id)
+	return context.WithValue(ctx, callUserKey{}, first(userKey))
+}
+
+// logCall writes one structured log line per call, at the error level for server errors, the
+// warning level for client errors and the info level otherwise, as the Logger middleware does.
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	slog.LogAttrs(ctx, level, "call",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
+
+// recovered reports a panic of a handler as an internal error, logging its stack trace.
+func recovered(ctx context.Context, value any) error {
+	return statusError(ctx, fmt.Errorf("panic: %v\n%s", value, debug.Stack()))
+}
+
+func unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	ctx = prepare(ctx)
+	defer func() {
+		if value := recover(); value != nil {
+			err = recovered(ctx, value)
+		}
+		logCall(ctx, info.FullMethod, start, err)
+	}()
+	return handler(ctx, req)
+}
+
+func streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	ctx := prepare(stream.Context())
+	defer func() {
+		if value := recover(); value != nil {
+			err = recovered(ctx, value)
+		}
+		logCall(ctx, info.FullMethod, start, err)
+	}()
+	return handler(srv, &preparedStream{ServerStream: stream, ctx: ctx})
+}
+
+// preparedStream is a server stream carrying the context prepared for its call.
+type preparedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *preparedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,64 @@
+// Package rpc serves the tasks over gRPC, with the TaskService defined in the taskpb package.
+// Calls go through the same data layer and validation rules as the REST API, identify the user
+// the same way, and are logged and traced like HTTP requests.
+package rpc
+
+import (
+	"context"
+	"net"
+	"task_manager/taskpb"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Server is the gRPC server of the API.
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+	// stopping is closed when the server stops, to end the Watch calls which would otherwise
+	// keep it from stopping gracefully.
+	stopping chan struct{}
+}
+
+// NewServer builds the gRPC server, serving TaskService along with the standard health service.
+func NewServer() *Server {
+	s := &Server{health: health.NewServer(), stopping: make(chan struct{})}
+	s.grpc = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptor),
+		grpc.ChainStreamInterceptor(streamInterceptor),
+	)
+	taskpb.RegisterTaskServiceServer(s.grpc, &taskService{stopping: s.stopping})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	return s
+}
+
+// Serve accepts connections on listener until the server stops.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpc.Serve(listener)
+}
+
+// Drain makes the health service report the server as not serving, as the readiness probe does
+// for HTTP, while the calls in progress finish.
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
+
+// Stop ends the Watch calls and waits for the other calls in progress until ctx is done, then
+// closes every connection.
+func (s *Server) Stop(ctx context.Context) {
+	close(s.stopping)
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
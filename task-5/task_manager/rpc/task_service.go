@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"task_manager/controllers"
+	"task_manager/data"
+	"task_manager/models"
+	"task_manager/problem"
+	"task_manager/taskpb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// taskService implements taskpb.TaskServiceServer on top of the data layer, validating the
+// requests as the controllers of the REST API do.
+type taskService struct {
+	taskpb.UnimplementedTaskServiceServer
+	// stopping is closed when the server stops.
+	stopping <-chan struct{}
+}
+
+// taskID parses the ID of a task, failing as the routes with an :id parameter do.
+func taskID(id string) (primitive.ObjectID, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return objID, problem.InvalidParameter("id", "must be a valid ObjectID")
+	}
+	return objID, nil
+}
+
+func (s *taskService) Get(ctx context.Context, req *taskpb.GetTaskRequest) (*taskpb.Task, error) {
+	id, err := taskID(req.GetId())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	task, err := data.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return toTask(*task), nil
+}
+
+func (s *taskService) List(req *taskpb.ListTasksRequest, stream taskpb.TaskService_ListServer) error {
+	ctx := stream.Context()
+	filter, err := controllers.NewTaskFilter(fromTaskFilter(req.GetFilter()), userFrom(ctx))
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	err = data.EachTask(ctx, filter, func(task models.Task) error {
+		return stream.Send(toTask(task))
+	})
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	return nil
+}
+
+func (s *taskService) Create(ctx context.Context, req *taskpb.CreateTaskRequest) (*taskpb.Task, error) {
+	task := fromTaskInput(req.GetTask())
+	if err := controllers.ValidateTask(task, true); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	created, err := data.AddNewTask(ctx, task, userFrom(ctx))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return toTask(*created), nil
+}
+
+func (s *taskService) Update(ctx context.Context, req *taskpb.UpdateTaskRequest) (*taskpb.Task, error) {
+	task := fromTaskInput(req.GetTask())
+	if err := controllers.ValidateTask(task, false); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	id, err := taskID(req.GetId())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	updated, err := data.UpdateTaskById(ctx, id, task, userFrom(ctx))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return toTask(*updated), nil
+}
+
+func (s *taskService) Delete(ctx context.Context, req *taskpb.DeleteTaskRequest) (*taskpb.DeleteTaskResponse, error) {
+	id, err := taskID(req.GetId())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	if _, err := data.DeleteTaskByID(ctx, id); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &taskpb.DeleteTaskResponse{}, nil
+}
+
+// Watch sends the changes of the tasks matching the filter, made through any server, until the client
+// cancels the call, the server stops or the change stream of the database fails.
+func (s *taskService) Watch(req *taskpb.WatchTasksRequest, stream taskpb.TaskService_WatchServer) error {
+	options := fromTaskFilter(req.GetFilter())
+	options.Sort = ""
+	ctx := stream.Context()
+	filter, err := controllers.NewTaskFilter(options, userFrom(ctx))
+	if err != nil {
+		return statusError(ctx, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := data.WatchTasks(ctx)
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	for {
+		select {
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "the server is stopping")
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				return status.Error(codes.Unavailable, "the changes of the tasks can't be read anymore")
+			}
+			if !filter.Matches(event.Task) {
+				continue
+			}
+			if err := stream.Send(&taskpb.TaskEvent{Kind: eventKinds[event.Kind], Task: toTask(event.Task)}); err != nil {
+				return err
+			}
+		}
+	}
+}
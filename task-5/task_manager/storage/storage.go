@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooLarge is returned when a blob exceeds the maximum size accepted by the storage.
+var ErrTooLarge = errors.New("file exceeds the maximum allowed size")
+
+// ErrNotFound is returned when no blob exists for the requested key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStorage stores binary content addressed by the SHA-256 hash of that content.
+// Storing the same content twice yields the same key and keeps a single copy.
+type BlobStorage interface {
+	// Put streams r into the storage and returns the hex encoded SHA-256 of the content and its size.
+	Put(r io.Reader) (key string, size int64, err error)
+	// Open returns a reader over the content stored under key. The caller must close it.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the content stored under key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
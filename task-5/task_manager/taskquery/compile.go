@@ -0,0 +1,133 @@
+package taskquery
+
+import (
+	"regexp"
+	"strings"
+	"task_manager/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Filter compiles the query to a MongoDB filter on the tasks collection.
+func (q *Query) Filter() bson.M {
+	if len(q.clauses) == 0 {
+		return bson.M{}
+	}
+	conditions := make(bson.A, 0, len(q.clauses))
+	for _, c := range q.clauses {
+		condition := c.filter()
+		if c.negate {
+			condition = bson.M{"$nor": bson.A{condition}}
+		}
+		conditions = append(conditions, condition)
+	}
+	return bson.M{"$and": conditions}
+}
+
+// Match reports whether a task matches the query. It agrees with the filter returned by Filter.
+func (q *Query) Match(task models.Task) bool {
+	for _, c := range q.clauses {
+		if c.match(task) == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clause) filter() bson.M {
+	switch c.field {
+	case "":
+		pattern := caseInsensitive(regexp.QuoteMeta(c.value))
+		return bson.M{"$or": bson.A{bson.M{"title": pattern}, bson.M{"description": pattern}}}
+	case "status":
+		return bson.M{"status": caseInsensitive("^" + regexp.QuoteMeta(c.value) + "$")}
+	case "tag":
+		return bson.M{"tags": c.value}
+	case "assignee":
+		return bson.M{"assignees": c.value}
+	case "priority":
+		return bson.M{"priority": c.value}
+	case "due":
+		return bson.M{"due_date": c.dateRange().filter()}
+	default:
+		return bson.M{"created_at": c.dateRange().filter()}
+	}
+}
+
+func (c clause) match(task models.Task) bool {
+	switch c.field {
+	case "":
+		value := strings.ToLower(c.value)
+		return strings.Contains(strings.ToLower(task.Title), value) ||
+			strings.Contains(strings.ToLower(task.Description), value)
+	case "status":
+		return strings.EqualFold(task.Status, c.value)
+	case "tag":
+		return contains(task.Tags, c.value)
+	case "assignee":
+		return contains(task.Assignees, c.value)
+	case "priority":
+		return task.Priority == c.value
+	case "due":
+		return c.dateRange().match(task.DueDate)
+	default:
+		return c.dateRange().match(task.CreatedAt)
+	}
+}
+
+func caseInsensitive(pattern string) bson.M {
+	return bson.M{"$regex": pattern, "$options": "i"}
+}
+
+// timeRange is the set of times t with from <= t < until, where a zero bound is open.
+// Unset dates, stored as the zero time, never belong to a range.
+type timeRange struct {
+	from  time.Time
+	until time.Time
+}
+
+// dateRange converts a date comparison to a time range. A date given without a time
+// stands for the whole day, so due<=2024-09-01 includes the tasks due during that day.
+func (c clause) dateRange() timeRange {
+	start, end := c.date, c.date.Add(time.Nanosecond)
+	if c.dateOnly {
+		end = c.date.AddDate(0, 0, 1)
+	}
+	switch c.op {
+	case "<":
+		return timeRange{until: start}
+	case "<=":
+		return timeRange{until: end}
+	case ">":
+		return timeRange{from: end}
+	case ">=":
+		return timeRange{from: start}
+	default:
+		return timeRange{from: start, until: end}
+	}
+}
+
+func (r timeRange) filter() bson.M {
+	condition := bson.M{"$gt": time.Time{}}
+	if !r.from.IsZero() {
+		condition["$gte"] = r.from
+	}
+	if !r.until.IsZero() {
+		condition["$lt"] = r.until
+	}
+	return condition
+}
+
+func (r timeRange) match(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if !r.from.IsZero() && t.Before(r.from) {
+		return false
+	}
+	if !r.until.IsZero() && !t.Before(r.until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package taskquery
+
+import (
+	"reflect"
+	"task_manager/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFilterAndMatch(t *testing.T) {
+	notes := models.Task{
+		Title:       "Write release notes",
+		Description: "For the 2.0 release",
+		Status:      "In Progress",
+		Tags:        []string{"backend"},
+		Assignees:   []string{"alice"},
+		Priority:    "P1",
+		DueDate:     time.Date(2024, time.September, 1, 10, 0, 0, 0, time.UTC),
+		CreatedAt:   day(2024, time.August, 20),
+	}
+	login := models.Task{
+		Title:     "Fix login (again)",
+		Status:    "done",
+		Tags:      []string{"frontend"},
+		Assignees: []string{"bob"},
+		Priority:  "P0",
+		CreatedAt: time.Date(2024, time.September, 10, 8, 0, 0, 0, time.UTC),
+	}
+	and := func(conditions ...interface{}) bson.M { return bson.M{"$and": bson.A(conditions)} }
+	text := func(pattern string) bson.M {
+		regex := bson.M{"$regex": pattern, "$options": "i"}
+		return bson.M{"$or": bson.A{bson.M{"title": regex}, bson.M{"description": regex}}}
+	}
+
+	tests := []struct {
+		query  string
+		filter bson.M
+		// matches tells whether notes and login match the query
+		matches [2]bool
+	}{
+		{"", bson.M{}, [2]bool{true, true}},
+		{`"release notes"`, and(text("release notes")), [2]bool{true, false}},
+		{"(again)", and(text(`\(again\)`)), [2]bool{false, true}},
+		{`status:"in progress"`, and(bson.M{"status": bson.M{"$regex": "^in progress$", "$options": "i"}}),
+			[2]bool{true, false}},
+		{"tag:Backend", and(bson.M{"tags": "backend"}), [2]bool{true, false}},
+		{"-tag:backend", and(bson.M{"$nor": bson.A{bson.M{"tags": "backend"}}}), [2]bool{false, true}},
+		{"assignee:me", and(bson.M{"assignees": "bob"}), [2]bool{false, true}},
+		{"priority:p0", and(bson.M{"priority": "P0"}), [2]bool{false, true}},
+		{"due<2024-09-02", and(bson.M{"due_date": bson.M{"$gt": time.Time{}, "$lt": day(2024, time.September, 2)}}),
+			[2]bool{true, false}},
+		{"due<=2024-09-01", and(bson.M{"due_date": bson.M{"$gt": time.Time{}, "$lt": day(2024, time.September, 2)}}),
+			[2]bool{true, false}},
+		{"due>2024-09-01", and(bson.M{"due_date": bson.M{"$gt": time.Time{}, "$gte": day(2024, time.September, 2)}}),
+			[2]bool{false, false}},
+		{"due:2024-09-01", and(bson.M{"due_date": bson.M{"$gt": time.Time{},
+			"$gte": day(2024, time.September, 1), "$lt": day(2024, time.September, 2)}}),
+			[2]bool{true, false}},
+		{"due:2024-09-01T10:00:00Z", and(bson.M{"due_date": bson.M{"$gt": time.Time{},
+			"$gte": notes.DueDate, "$lt": notes.DueDate.Add(time.Nanosecond)}}),
+			[2]bool{true, false}},
+		{"created>=today", and(bson.M{"created_at": bson.M{"$gt": time.Time{}, "$gte": day(2024, time.September, 10)}}),
+			[2]bool{false, true}},
+		{"created<-1w", and(bson.M{"created_at": bson.M{"$gt": time.Time{}, "$lt": day(2024, time.September, 3)}}),
+			[2]bool{true, false}},
+		{"login -status:done", and(text("login"),
+			bson.M{"$nor": bson.A{bson.M{"status": bson.M{"$regex": "^done$", "$options": "i"}}}}),
+			[2]bool{false, false}},
+	}
+	for _, test := range tests {
+		q, err := ParseIn(test.query, testEnv)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.query, err)
+			continue
+		}
+		if filter := q.Filter(); !reflect.DeepEqual(filter, test.filter) {
+			t.Errorf("%q: got filter %v, want %v", test.query, filter, test.filter)
+		}
+		for i, task := range []models.Task{notes, login} {
+			if got := q.Match(task); got != test.matches[i] {
+				t.Errorf("%q: Match(%q) = %v, want %v", test.query, task.Title, got, test.matches[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package taskquery implements the small query language used to filter tasks, such as
+//
+//	status:done due<2024-09-01 tag:backend "release notes" -assignee:bob
+//
+// A query is a list of clauses separated by spaces, all of which must match:
+//   - field:value matches tasks whose field equals the value. Fields are status, tag,
+//     assignee, priority, due and created; dates are given as YYYY-MM-DD or RFC 3339,
+//     or relative to the time of parsing as today, yesterday, tomorrow, now, or a number
+//     of days or weeks from today such as +7d or -2w. assignee:me stands for the current user
+//   - due and created also accept the comparisons <, <=, > and >=
+//   - a bare word or a "quoted phrase" matches tasks containing it in their title or description
+//   - a clause prefixed with - matches the tasks the clause alone would not match
+//
+// Values containing spaces can be quoted, as in status:"in progress". Free text, status and tag
+// matching is case insensitive.
+package taskquery
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query is a parsed query. It can be compiled to a MongoDB filter with Filter
+// or evaluated against a task in memory with Match.
+type Query struct {
+	clauses []clause
+}
+
+type clause struct {
+	negate bool
+	field  string // empty for free text
+	op     string
+	value  string
+	date   time.Time
+	// dateOnly is true when the date was given without a time, so it stands for the whole day.
+	dateOnly bool
+}
+
+// ParseError describes why a query could not be parsed.
+// Position is the 1-based column, counted in characters, where the problem was found.
+type ParseError struct {
+	Position int
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Message, e.Position)
+}
+
+// fieldOps lists the known fields together with the operators each of them accepts.
+var fieldOps = map[string][]string{
+	"status":   {":"},
+	"tag":      {":"},
+	"assignee": {":"},
+	"priority": {":"},
+	"due":      {":", "<", "<=", ">", ">="},
+	"created":  {":", "<", "<=", ">", ">="},
+}
+
+// Env is the context a query is parsed in, used to resolve relative values.
+type Env struct {
+	// User is the user "assignee:me" refers to. When empty, "me" is rejected.
+	User string
+	// Now is the time relative dates are computed from, in UTC.
+	Now time.Time
+}
+
+// relativeDatePattern matches relative dates such as +7d or -2w.
+var relativeDatePattern = regexp.MustCompile(`^([+-]\d+)([dw])$`)
+
+// maxRelativeDays bounds relative dates to about a thousand years from today, well within
+// the dates time.Time and MongoDB can represent.
+const maxRelativeDays = 366_000
+
+// Parse parses a query without a current user, resolving relative dates from the current time.
+// An empty query matches every task. It returns a *ParseError when the query is malformed.
+func Parse(input string) (*Query, error) {
+	return ParseIn(input, Env{Now: time.Now()})
+}
+
+// ParseIn parses a query in the given environment.
+// An empty query matches every task. It returns a *ParseError when the query is malformed.
+func ParseIn(input string, env Env) (*Query, error) {
+	p := parser{input: input, env: env}
+	var q Query
+	for {
+		p.skipSpaces()
+		if p.done() {
+			return &q, nil
+		}
+		c, err := p.clause()
+		if err != nil {
+			return nil, err
+		}
+		q.clauses = append(q.clauses, c)
+	}
+}
+
+type parser struct {
+	input string
+	pos   int // byte offset of the next character to read
+	env   Env
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+// errorAt builds a ParseError located at the given byte offset.
+func (p *parser) errorAt(offset int, format string, args ...interface{}) error {
+	return &ParseError{
+		Position: utf8.RuneCountInString(p.input[:offset]) + 1,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// clause parses a single, possibly negated, clause.
+func (p *parser) clause() (clause, error) {
+	var c clause
+	if p.peek() == '-' {
+		c.negate = true
+		p.pos++
+		if p.done() || unicode.IsSpace(rune(p.peek())) {
+			return c, p.errorAt(p.pos-1, "expected a clause after '-'")
+		}
+	}
+
+	if p.peek() == '"' {
+		phrase, err := p.quoted()
+		if err != nil {
+			return c, err
+		}
+		c.value = phrase
+		return c, nil
+	}
+
+	start := p.pos
+	word := p.word()
+	if p.done() || !isOperatorStart(p.peek()) {
+		c.value = word
+		return c, nil
+	}
+
+	c.field = strings.ToLower(word)
+	ops, known := fieldOps[c.field]
+	if !known {
+		return c, p.errorAt(start, "unknown field %q", word)
+	}
+	opStart := p.pos
+	c.op = p.operator()
+	if !contains(ops, c.op) {
+		return c, p.errorAt(opStart, "operator %q is not supported by field %q", c.op, c.field)
+	}
+
+	valueStart := p.pos
+	if p.done() || unicode.IsSpace(rune(p.peek())) {
+		return c, p.errorAt(valueStart, "missing value for field %q", c.field)
+	}
+	var err error
+	if p.peek() == '"' {
+		if c.value, err = p.quoted(); err != nil {
+			return c, err
+		}
+	} else {
+		c.value = p.value()
+	}
+	if err := c.check(p.env); err != nil {
+		return c, p.errorAt(valueStart, "%s", err.Error())
+	}
+	return c, nil
+}
+
+// word reads characters up to the next space or operator.
+func (p *parser) word() string {
+	start := p.pos
+	for !p.done() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || (p.pos > start && isOperatorStart(p.input[p.pos])) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+// value reads an unquoted field value, which ends at the next space only,
+// so that values such as RFC 3339 times may contain colons.
+func (p *parser) value() string {
+	start := p.pos
+	for !p.done() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+// quoted reads a double quoted string, the opening quote being the next character.
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	end := strings.IndexByte(p.input[start+1:], '"')
+	if end < 0 {
+		return "", p.errorAt(start, "unterminated quote")
+	}
+	p.pos = start + 1 + end + 1
+	value := p.input[start+1 : start+1+end]
+	if strings.TrimSpace(value) == "" {
+		return "", p.errorAt(start, "empty quotes")
+	}
+	return value, nil
+}
+
+// operator reads one of :, <, <=, > and >=.
+func (p *parser) operator() string {
+	op := string(p.peek())
+	p.pos++
+	if (op == "<" || op == ">") && !p.done() && p.peek() == '=' {
+		op += "="
+		p.pos++
+	}
+	return op
+}
+
+func isOperatorStart(b byte) bool {
+	return b == ':' || b == '<' || b == '>'
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// check validates and normalizes the value of a field clause, resolving relative values in env.
+func (c *clause) check(env Env) error {
+	switch c.field {
+	case "assignee":
+		if c.value == "me" {
+			if env.User == "" {
+				return fmt.Errorf("assignee:me requires a current user")
+			}
+			c.value = env.User
+		}
+	case "priority":
+		c.value = strings.ToUpper(c.value)
+		switch c.value {
+		case "P0", "P1", "P2", "P3":
+		default:
+			return fmt.Errorf("priority must be one of P0, P1, P2 or P3")
+		}
+	case "tag":
+		c.value = strings.ToLower(c.value)
+	case "due", "created":
+		if relative, err := c.resolveRelative(env.Now.UTC()); relative || err != nil {
+			return err
+		}
+		if date, err := time.Parse(time.DateOnly, c.value); err == nil {
+			c.date, c.dateOnly = date, true
+			return nil
+		}
+		date, err := time.Parse(time.RFC3339, c.value)
+		if err != nil {
+			return fmt.Errorf("%s must be a YYYY-MM-DD date or an RFC 3339 time", c.field)
+		}
+		c.date = date
+	}
+	return nil
+}
+
+// resolveRelative sets the date of the clause when its value is a relative date
+// and reports whether it was one. It fails when the date is too far from today.
+func (c *clause) resolveRelative(now time.Time) (bool, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	c.dateOnly = true
+	switch strings.ToLower(c.value) {
+	case "now":
+		c.date, c.dateOnly = now, false
+	case "today":
+		c.date = today
+	case "yesterday":
+		c.date = today.AddDate(0, 0, -1)
+	case "tomorrow":
+		c.date = today.AddDate(0, 0, 1)
+	default:
+		match := relativeDatePattern.FindStringSubmatch(strings.ToLower(c.value))
+		if match == nil {
+			c.dateOnly = false
+			return false, nil
+		}
+		days, err := strconv.Atoi(match[1])
+		unit := 1
+		if match[2] == "w" {
+			unit = 7
+		}
+		if err != nil || days > maxRelativeDays/unit || days < -maxRelativeDays/unit {
+			return false, fmt.Errorf("%s is too far from today", c.value)
+		}
+		c.date = today.AddDate(0, 0, days*unit)
+	}
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package taskquery
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEnv is the environment the tests parse queries in: relative dates are resolved from
+// Tuesday 10 September 2024, 15:04 UTC, and "me" is bob.
+var testEnv = Env{User: "bob", Now: time.Date(2024, time.September, 10, 15, 4, 0, 0, time.UTC)}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		env      Env
+		position int
+		message  string
+	}{
+		{"foo:bar", testEnv, 1, `unknown field "foo"`},
+		{"tag:x Foo<1", testEnv, 7, `unknown field "Foo"`},
+		{"tag<x", testEnv, 4, `operator "<" is not supported by field "tag"`},
+		{"status>=done", testEnv, 7, `operator ">=" is not supported by field "status"`},
+		{"status:", testEnv, 8, `missing value for field "status"`},
+		{"due< 2024-09-01", testEnv, 5, `missing value for field "due"`},
+		{`"release notes`, testEnv, 1, "unterminated quote"},
+		{`tag:"backend`, testEnv, 5, "unterminated quote"},
+		{`tag:"  "`, testEnv, 5, "empty quotes"},
+		{"- tag:x", testEnv, 1, "expected a clause after '-'"},
+		{"tag:x -", testEnv, 7, "expected a clause after '-'"},
+		{"due:tomorrowish", testEnv, 5, "due must be a YYYY-MM-DD date or an RFC 3339 time"},
+		{"created>2024-13-01", testEnv, 9, "created must be a YYYY-MM-DD date or an RFC 3339 time"},
+		{"due<+99999999999999999999d", testEnv, 5, "+99999999999999999999d is too far from today"},
+		{"created>-52300w", testEnv, 9, "-52300w is too far from today"},
+		{"priority:P5", testEnv, 10, "priority must be one of P0, P1, P2 or P3"},
+		{"assignee:me", Env{Now: testEnv.Now}, 10, "assignee:me requires a current user"},
+		// Positions count characters, not bytes
+		{"été tag<x", testEnv, 8, `operator "<" is not supported by field "tag"`},
+	}
+	for _, test := range tests {
+		_, err := ParseIn(test.query, test.env)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: got error %v, want a *ParseError", test.query, err)
+			continue
+		}
+		if parseErr.Position != test.position || parseErr.Message != test.message {
+			t.Errorf("%q: got %q at column %d, want %q at column %d",
+				test.query, parseErr.Message, parseErr.Position, test.message, test.position)
+		}
+		if !strings.HasSuffix(err.Error(), " at column "+strconv.Itoa(test.position)) {
+			t.Errorf("%q: got error text %q, missing its column", test.query, err.Error())
+		}
+	}
+}
+
+func TestParseClauses(t *testing.T) {
+	tests := []struct {
+		query   string
+		clauses []clause
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"login", []clause{{value: "login"}}},
+		{`"release notes" -draft`, []clause{{value: "release notes"}, {negate: true, value: "draft"}}},
+		{`status:"in progress"`, []clause{{field: "status", op: ":", value: "in progress"}}},
+		{"TAG:Backend", []clause{{field: "tag", op: ":", value: "backend"}}},
+		{"-assignee:me", []clause{{negate: true, field: "assignee", op: ":", value: "bob"}}},
+		{"priority:p1", []clause{{field: "priority", op: ":", value: "P1"}}},
+		{"due<=2024-09-01", []clause{{field: "due", op: "<=", value: "2024-09-01",
+			date: time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"created>2024-09-01T10:30:00Z", []clause{{field: "created", op: ">", value: "2024-09-01T10:30:00Z",
+			date: time.Date(2024, time.September, 1, 10, 30, 0, 0, time.UTC)}}},
+		{"due<now", []clause{{field: "due", op: "<", value: "now", date: testEnv.Now}}},
+		{"due>=Today", []clause{{field: "due", op: ">=", value: "Today",
+			date: time.Date(2024, time.September, 10, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"due:yesterday", []clause{{field: "due", op: ":", value: "yesterday",
+			date: time.Date(2024, time.September, 9, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"due:tomorrow", []clause{{field: "due", op: ":", value: "tomorrow",
+			date: time.Date(2024, time.September, 11, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"due<+7d", []clause{{field: "due", op: "<", value: "+7d",
+			date: time.Date(2024, time.September, 17, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"created>=-2W", []clause{{field: "created", op: ">=", value: "-2W",
+			date: time.Date(2024, time.August, 27, 0, 0, 0, 0, time.UTC), dateOnly: true}}},
+		{"due<+52000w", []clause{{field: "due", op: "<", value: "+52000w",
+			date: time.Date(2024, time.September, 10, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 364000), dateOnly: true}}},
+	}
+	for _, test := range tests {
+		q, err := ParseIn(test.query, testEnv)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.query, err)
+			continue
+		}
+		if len(q.clauses) != len(test.clauses) {
+			t.Errorf("%q: got %d clauses, want %d", test.query, len(q.clauses), len(test.clauses))
+			continue
+		}
+		for i, got := range q.clauses {
+			want := test.clauses[i]
+			if got.negate != want.negate || got.field != want.field || got.op != want.op ||
+				got.value != want.value || !got.date.Equal(want.date) || got.dateOnly != want.dateOnly {
+				t.Errorf("%q: clause %d is %+v, want %+v", test.query, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package tracing sets up OpenTelemetry tracing: the W3C trace context propagation of incoming
+// requests and the export of the spans recorded for the routes and the MongoDB operations.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// ServiceName names the service in the spans, unless OTEL_SERVICE_NAME is set.
+const ServiceName = "task-manager"
+
+// Exporters accepted in the OTEL_TRACES_EXPORTER environment variable.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup installs the global tracer provider and propagator. The exporter is chosen by the
+// OTEL_TRACES_EXPORTER environment variable: "none" (the default) records nothing, "stdout"
+// prints the spans as JSON and "otlp" sends them over OTLP/HTTP to the collector named by the
+// standard OTEL_EXPORTER_OTLP_ENDPOINT variable, http://localhost:4318 by default.
+// The returned function flushes the pending spans and must be called before exiting.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER"))); name {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q, expected none, stdout or otlp", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	// Resource attributes given in the environment, such as OTEL_SERVICE_NAME, take precedence.
+	if res, err = resource.Merge(res, resource.Environment()); err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}